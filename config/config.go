@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// Config stacks TreeMap layers; layers added later take priority. Lookups
+// read a merged view (RFC 7396 semantics: maps merge, slices and scalars
+// are replaced by the higher layer).
+type Config struct {
+	mu     sync.RWMutex
+	layers []layer
+	merged goutils.TreeMapImpl
+}
+
+type layer struct {
+	name string
+	tree goutils.TreeMapImpl
+}
+
+func New() *Config {
+	return &Config{}
+}
+
+// ------------------- Layers -------------------
+
+// Add pushes a layer on top of the stack.
+func (c *Config) Add(name string, tree goutils.TreeMapImpl) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.layers = append(c.layers, layer{name: name, tree: tree})
+	c.merged = nil
+}
+
+// AddDefaults pushes built-in defaults, usually the first layer.
+func (c *Config) AddDefaults(data any) {
+	c.Add("defaults", goutils.NewTreeMap(data))
+}
+
+// AddFile pushes the JSON document at path as layer "file:<path>".
+func (c *Config) AddFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	c.Add("file:"+path, goutils.NewTreeMap(data))
+	return nil
+}
+
+// AddEnv pushes the variables starting with prefix + "_". The rest of the
+// name is lowercased and "__" separates levels: APP_DB__HOST is db.host.
+// Values stay strings; convert them with the As* accessors or GetAs.
+func (c *Config) AddEnv(prefix string) error {
+	flat := map[string]any{}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		key, ok := strings.CutPrefix(name, prefix+"_")
+		if !ok || key == "" {
+			continue
+		}
+		flat[strings.ToLower(key)] = value
+	}
+	tree := goutils.Unflatten(flat, goutils.FlattenOptions{Separator: "__"})
+	if !tree.Exists() {
+		return fmt.Errorf("config env %s: conflicting variables", prefix)
+	}
+	c.Add("env", tree)
+	return nil
+}
+
+// AddFlags pushes the flags that were set on the command line (fs must be
+// parsed). Dots in flag names separate levels: -db.host is db.host.
+func (c *Config) AddFlags(fs *flag.FlagSet) error {
+	flat := map[string]any{}
+	fs.Visit(func(f *flag.Flag) {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			flat[f.Name] = getter.Get()
+		} else {
+			flat[f.Name] = f.Value.String()
+		}
+	})
+	tree := goutils.Unflatten(flat)
+	if !tree.Exists() {
+		return fmt.Errorf("config flags: conflicting flag names")
+	}
+	c.Add("flags", tree)
+	return nil
+}
+
+// ------------------- Lookups -------------------
+
+// Tree returns the merged view of every layer. Treat it as read-only; it
+// is rebuilt when a layer is added.
+func (c *Config) Tree() goutils.TreeMapImpl {
+	c.mu.RLock()
+	merged := c.merged
+	c.mu.RUnlock()
+	if merged != nil {
+		return merged
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.merged == nil {
+		merged := goutils.NewTreeMap()
+		for _, l := range c.layers {
+			merged.Merge(l.tree)
+		}
+		c.merged = merged
+	}
+	return c.merged
+}
+
+// Get resolves path through the stack.
+func (c *Config) Get(path string) goutils.TreeMapImpl {
+	return c.Tree().Get(path)
+}
+
+// Source returns the name of the layer the value at path comes from, or ""
+// when the path is not defined.
+func (c *Config) Source(path string) string {
+	if !c.Tree().IsDefined(path) {
+		return ""
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		if c.layers[i].tree.IsDefined(path) {
+			return c.layers[i].name
+		}
+	}
+	return ""
+}
+
+// Layers returns the layer names from lowest to highest priority.
+func (c *Config) Layers() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, len(c.layers))
+	for i, l := range c.layers {
+		names[i] = l.name
+	}
+	return names
+}
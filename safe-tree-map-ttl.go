@@ -0,0 +1,381 @@
+package goutils
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ------------------- Expiring Paths -------------------
+
+// SetWithTTL writes value at path and removes it once ttl has passed. Each
+// path expires on its own, so `tokens.acme` and `tokens.globex` can carry
+// different deadlines. Expired paths are evicted by the next read of this
+// tree, or by a janitor (see StartJanitor). Any write that replaces or
+// deletes the value at the path (Set, Merge, Update, Increment, ...) drops
+// the deadline, while writes below it keep it; a ttl <= 0 writes without one. Paths through a
+// slice are refused, since slice elements move when the slice is spliced.
+func (s *SafeTreeMap) SetWithTTL(path string, value any, ttl time.Duration) TreeMapImpl {
+	if ttl <= 0 {
+		return s.Set(path, value)
+	}
+	if s.err != nil {
+		return &TreeMap{err: s.err}
+	}
+	full := s.abs(relPath(path))
+	if !isConcretePath(full) {
+		return &TreeMap{err: fmt.Errorf("path %q: SetWithTTL needs a concrete path", path)}
+	}
+	scope, unlock := s.st.lockPath(full)
+	defer unlock()
+	res := s.apply(scope, func(node *ImmutableTreeMap) TreeMapImpl {
+		next := node.Set(path, value)
+		if next.Err() == nil && crossesSlice(next.(*ImmutableTreeMap).rootTree().value, full) {
+			return &TreeMap{err: fmt.Errorf("path %q: SetWithTTL cannot expire slice elements", path)}
+		}
+		return next
+	})
+	if res.Err() != nil {
+		return res
+	}
+	s.st.ttl.forget(full)
+	s.st.ttl.set(full, time.Now().Add(ttl))
+	return s
+}
+
+// TTL returns the time left before path expires, and false when path has
+// no deadline.
+func (s *SafeTreeMap) TTL(path string) (time.Duration, bool) {
+	rel, err := parseConcretePath(path)
+	if err != nil || s.err != nil {
+		return 0, false
+	}
+	at, ok := s.st.ttl.deadline(append(slices.Clone(s.path), rel...))
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(at), 0), true
+}
+
+// OnEvict calls fn with the path and the removed value each time a path
+// expires. fn runs in the goroutine that noticed the expiry, after every
+// lock is released. The returned func unregisters fn.
+func (s *SafeTreeMap) OnEvict(fn func(path string, value TreeMapImpl)) func() {
+	return s.st.ttl.onEvict(fn)
+}
+
+// StartJanitor evicts expired paths every interval from a goroutine of its
+// own, so memory is released even when nothing reads the tree. Call stop to
+// end it.
+func (s *SafeTreeMap) StartJanitor(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.st.expire(time.Now(), true)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// crossesSlice reports whether a segment of path indexes into a slice in
+// root.
+func crossesSlice(root any, path []pathSegment) bool {
+	v := root
+	for _, seg := range path {
+		switch c := v.(type) {
+		case []any:
+			return true
+		case map[string]any:
+			v = c[seg.key]
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// ------------------- Eviction -------------------
+
+// expireDue evicts expired paths if the earliest deadline has passed; it
+// costs one atomic load when nothing is due. It never waits for a lock: a
+// path whose scope is held, e.g. by Update while fn runs (and fn may read
+// the tree), is left to the next read or the janitor.
+func (st *safeState) expireDue() {
+	if next := st.ttl.next.Load(); next != 0 && time.Now().UnixNano() >= next {
+		st.expire(time.Now(), false)
+	}
+}
+
+// expire evicts the paths expired by now. Without wait it stops at the first
+// path whose scope is locked.
+func (st *safeState) expire(now time.Time, wait bool) {
+	for {
+		e := st.ttl.due(now)
+		if e == nil || !st.evict(e, now, wait) {
+			return
+		}
+	}
+}
+
+// evict deletes the path of e unless it was rewritten in the meantime. The
+// shard lock keeps writers of the same path out while we check. Without
+// wait it reports false when the lock is taken.
+func (st *safeState) evict(e *ttlEntry, now time.Time, wait bool) bool {
+	root := &SafeTreeMap{st: st}
+	scope, unlock, ok := st.tryLockPath(e.segs)
+	if !ok {
+		if !wait {
+			return false
+		}
+		scope, unlock = st.lockPath(e.segs)
+	}
+	if !st.ttl.take(e, now) {
+		unlock()
+		return true
+	}
+	var removed TreeMapImpl
+	res := root.apply(scope, func(node *ImmutableTreeMap) TreeMapImpl {
+		removed = node.tm.getSegments(e.segs)
+		return node.deleteSegments(e.segs)
+	})
+	if res.Err() == nil {
+		st.ttl.forget(e.segs)
+	}
+	unlock()
+
+	if res.Err() != nil {
+		// already gone, e.g. removed by a whole-tree write
+		return true
+	}
+	path := formatPath(e.segs)
+	for _, fn := range st.ttl.callbacks() {
+		fn(path, &ImmutableTreeMap{tm: removed.(*TreeMap)})
+	}
+	return true
+}
+
+// ------------------- Deadline Index -------------------
+
+type ttlEntry struct {
+	segs  []pathSegment
+	key   string
+	at    time.Time
+	index int
+}
+
+// ttlIndex keeps deadlines by path in a min-heap. Its mutex is taken after
+// shard locks, never before.
+type ttlIndex struct {
+	mu       sync.Mutex
+	byPath   map[string]*ttlEntry
+	queue    ttlQueue
+	next     atomic.Int64 // earliest deadline in unix nanos, 0 if none
+	nextID   int
+	evictFns map[int]func(string, TreeMapImpl)
+}
+
+func (t *ttlIndex) set(segs []pathSegment, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.byPath == nil {
+		t.byPath = map[string]*ttlEntry{}
+	}
+	key := formatPath(segs)
+	if e, ok := t.byPath[key]; ok {
+		e.at = at
+		heap.Fix(&t.queue, e.index)
+	} else {
+		e := &ttlEntry{segs: slices.Clone(segs), key: key, at: at}
+		t.byPath[key] = e
+		heap.Push(&t.queue, e)
+	}
+	t.updateNext()
+}
+
+// forget drops the deadlines at or below segs. Without deadlines it costs
+// one atomic load.
+func (t *ttlIndex) forget(segs []pathSegment) {
+	if t.next.Load() == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.byPath) == 0 {
+		return
+	}
+	for key, e := range t.byPath {
+		if hasPathPrefix(e.segs, segs) {
+			delete(t.byPath, key)
+			heap.Remove(&t.queue, e.index)
+		}
+	}
+	t.updateNext()
+}
+
+// dropChanged drops the deadlines at or below scope whose value a write
+// removed or replaced. A map or slice written into keeps its deadline, as
+// with a Set below the path.
+func (t *ttlIndex) dropChanged(scope []pathSegment, old, next any) {
+	if t.next.Load() == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, e := range t.byPath {
+		if hasPathPrefix(e.segs, scope) && replaced(old, next, e.segs) {
+			delete(t.byPath, key)
+			heap.Remove(&t.queue, e.index)
+		}
+	}
+	t.updateNext()
+}
+
+// replaced reports whether the value at segs is gone from next or is a
+// different value from the one in old.
+func replaced(old, next any, segs []pathSegment) bool {
+	nv, ok := lookupValue(next, segs)
+	if !ok {
+		return true
+	}
+	ov, _ := lookupValue(old, segs)
+	switch ov.(type) {
+	case map[string]any:
+		_, ok := nv.(map[string]any)
+		return !ok
+	case []any:
+		_, ok := nv.([]any)
+		return !ok
+	}
+	return !valuesEqual(ov, nv, false)
+}
+
+func (t *ttlIndex) deadline(segs []pathSegment) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.byPath[formatPath(segs)]; ok {
+		return e.at, true
+	}
+	return time.Time{}, false
+}
+
+// due returns the earliest entry if it expired by now.
+func (t *ttlIndex) due(now time.Time) *ttlEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.queue) == 0 || t.queue[0].at.After(now) {
+		return nil
+	}
+	return t.queue[0]
+}
+
+// take reports whether e is still registered and expired, in which case the
+// caller is the one to evict it.
+func (t *ttlIndex) take(e *ttlEntry, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.byPath[e.key] != e || e.at.After(now) {
+		return false
+	}
+	delete(t.byPath, e.key)
+	heap.Remove(&t.queue, e.index)
+	t.updateNext()
+	return true
+}
+
+func (t *ttlIndex) updateNext() {
+	if len(t.queue) == 0 {
+		t.next.Store(0)
+		return
+	}
+	t.next.Store(t.queue[0].at.UnixNano())
+}
+
+func (t *ttlIndex) onEvict(fn func(string, TreeMapImpl)) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.evictFns == nil {
+		t.evictFns = map[int]func(string, TreeMapImpl){}
+	}
+	id := t.nextID
+	t.nextID++
+	t.evictFns[id] = fn
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.evictFns, id)
+	}
+}
+
+func (t *ttlIndex) callbacks() []func(string, TreeMapImpl) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]int, 0, len(t.evictFns))
+	for id := range t.evictFns {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	out := make([]func(string, TreeMapImpl), len(ids))
+	for i, id := range ids {
+		out[i] = t.evictFns[id]
+	}
+	return out
+}
+
+// copyUnder registers in dst the deadlines at or below prefix, relative to
+// prefix.
+func (t *ttlIndex) copyUnder(prefix []pathSegment, dst *ttlIndex) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.byPath {
+		if hasPathPrefix(e.segs, prefix) && len(e.segs) > len(prefix) {
+			dst.set(e.segs[len(prefix):], e.at)
+		}
+	}
+}
+
+func hasPathPrefix(segs, prefix []pathSegment) bool {
+	if len(segs) < len(prefix) {
+		return false
+	}
+	for i, seg := range prefix {
+		if seg.key != segs[i].key {
+			return false
+		}
+	}
+	return true
+}
+
+// ttlQueue implements heap.Interface ordered by deadline.
+type ttlQueue []*ttlEntry
+
+func (q ttlQueue) Len() int           { return len(q) }
+func (q ttlQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+func (q ttlQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+
+func (q *ttlQueue) Push(x any) {
+	e := x.(*ttlEntry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *ttlQueue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return e
+}
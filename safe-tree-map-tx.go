@@ -0,0 +1,114 @@
+package goutils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ------------------- Transactions -------------------
+
+// Update runs fn on a private copy of this node and publishes it as one
+// version, so other goroutines see either none or all of its writes. When fn
+// returns an error or panics, nothing is published. Writers of paths that
+// overlap this node wait for fn. tx is a plain TreeMap: check Err() on its
+// results. Read and write through tx only; the SafeTreeMap does not see
+// tx's writes, and writing to it from inside fn deadlocks.
+//
+//	err := accounts.Update(func(tx goutils.TreeMapImpl) error {
+//		a, b := tx.Get("a").AsIntOr(0), tx.Get("b").AsIntOr(0)
+//		if a < 10 {
+//			return errors.New("insufficient funds")
+//		}
+//		tx.Set("a", a-10)
+//		return tx.Set("b", b+10).Err()
+//	})
+func (s *SafeTreeMap) Update(fn func(tx TreeMapImpl) error) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.st.expireDue()
+	scope, unlock := s.st.lockPath(s.path)
+	defer unlock()
+	node := s.current()
+	if node.tm.err != nil {
+		return node.tm.err
+	}
+	tx := &TreeMap{value: deepClone(node.tm.value), path: slices.Clone(s.path), conv: node.rootTree().conv}
+	tx.root = tx
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return s.apply(scope, func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.setSegments(nil, tx.value)
+	}).Err()
+}
+
+// ------------------- Atomic Primitives -------------------
+
+// CompareAndSet writes value at path only if the current value equals old
+// (numbers compare by value). A nil old matches a missing path. It reports
+// whether the write happened.
+func (s *SafeTreeMap) CompareAndSet(path string, old, value any) bool {
+	if s.err != nil {
+		return false
+	}
+	s.st.expireDue()
+	scope, unlock := s.st.lockPath(s.abs(relPath(path)))
+	defer unlock()
+	return s.apply(scope, func(node *ImmutableTreeMap) TreeMapImpl {
+		current := node.Get(path)
+		switch err := current.Err(); {
+		case err == nil:
+			if !valuesEqual(current.getValue(), normalizeToDefault(old), true) {
+				return &TreeMap{err: errNotEqual}
+			}
+		case !isMissing(err) || old != nil:
+			return &TreeMap{err: errNotEqual}
+		}
+		return node.Set(path, value)
+	}).Err() == nil
+}
+
+var errNotEqual = errors.New("value does not match")
+
+// Increment adds delta to the integer at path, starting from 0 when the path
+// is missing, and returns the new value. Fractional numbers and non-numbers
+// are refused.
+func (s *SafeTreeMap) Increment(path string, delta int64) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.st.expireDue()
+	scope, unlock := s.st.lockPath(s.abs(relPath(path)))
+	defer unlock()
+	var n int64
+	res := s.apply(scope, func(node *ImmutableTreeMap) TreeMapImpl {
+		current := node.Get(path)
+		n = 0
+		if err := current.Err(); err != nil && !isMissing(err) {
+			return &TreeMap{err: err}
+		} else if err == nil {
+			v := current.getValue()
+			if !isNumber(v) {
+				return &TreeMap{err: mismatch(current.Path(), "integer", v)}
+			}
+			if n, err = strictNumbers.toInt(v); err != nil {
+				return &TreeMap{err: &PathError{Path: current.Path(), Err: err}}
+			}
+		}
+		if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+			return &TreeMap{err: &PathError{Path: current.Path(), Err: fmt.Errorf("%w: %d%+d", ErrOutOfRange, n, delta)}}
+		}
+		n += delta
+		return node.Set(path, n)
+	})
+	if err := res.Err(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// strictNumbers reads integers without truncating fractions.
+var strictNumbers = NewConverter(CoerceStrict)
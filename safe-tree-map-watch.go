@@ -0,0 +1,342 @@
+package goutils
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ------------------- Change Subscriptions -------------------
+
+// ChangeEvent describes one leaf (a scalar or an empty container) that a
+// committed write added, removed or modified. Path is relative to the root
+// of the tree, like Path(); Old and New are detached copies.
+type ChangeEvent = Change
+
+// Watch subscribes to changes at or below pattern, which is relative to this
+// node and may use `*`, `[*]` and `..` segments. Events arrive on the
+// returned channel, in commit order for each path, until cancel is called,
+// which also closes it. Pending events are queued without limit, so a
+// watcher that stops reading must cancel. An invalid pattern returns a
+// closed channel.
+//
+//	events, cancel := cfg.Watch("db.*")
+//	defer cancel()
+//	for ev := range events {
+//		log.Printf("%s changed to %v", ev.Path, ev.New)
+//	}
+func (s *SafeTreeMap) Watch(pattern string) (<-chan ChangeEvent, func()) {
+	ch := make(chan ChangeEvent)
+	w, err := s.subscribe(pattern)
+	if err != nil {
+		close(ch)
+		return ch, func() {}
+	}
+	w.deliver = func(ev ChangeEvent) {
+		select {
+		case ch <- ev:
+		case <-w.done:
+		}
+	}
+	go func() {
+		defer close(ch)
+		w.run()
+	}()
+	return ch, w.cancel
+}
+
+// OnChange calls fn for every change at or below pattern, in commit order
+// for each path, from a goroutine of its own. fn runs without any lock held,
+// so it may read and write the tree. cancel stops delivery but does not wait
+// for a call that is already running.
+func (s *SafeTreeMap) OnChange(pattern string, fn func(ChangeEvent)) func() {
+	w, err := s.subscribe(pattern)
+	if err != nil {
+		return func() {}
+	}
+	w.deliver = fn
+	go w.run()
+	return w.cancel
+}
+
+func (s *SafeTreeMap) subscribe(pattern string) (*watcher, error) {
+	segs, err := parsePath(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		if seg.kind == segmentSlice || seg.kind == segmentFilter {
+			return nil, fmt.Errorf("watch pattern %q: slices and filters are not supported", pattern)
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	w := &watcher{
+		pattern: append(slices.Clone(s.path), segs...),
+		hub:     &s.st.hub,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.st.hub.add(w)
+	return w, nil
+}
+
+// ------------------- Tracking Writes -------------------
+
+// publish queues the leaves below scope that differ between two versions.
+// It runs under the shard lock of scope, so events for overlapping paths are
+// queued in commit order; delivery happens later, outside any lock. A scope
+// never ends below a slice (see writeScope), so a splice or a padded write
+// is diffed from the whole slice, with index paths such as `items[1]`.
+func (st *safeState) publish(scope []pathSegment, old, next *TreeMap) {
+	if !st.hub.active() {
+		return
+	}
+	before, inOld := lookupValue(old.value, scope)
+	after, inNew := lookupValue(next.value, scope)
+	var changes []watchChange
+	diffLeaves(scope, before, inOld, after, inNew, &changes)
+	st.hub.publish(changes)
+}
+
+func lookupValue(v any, segs []pathSegment) (any, bool) {
+	for _, seg := range segs {
+		var err error
+		if v, _, err = stepInto(v, seg); err != nil {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+type watchChange struct {
+	segs  []pathSegment
+	event ChangeEvent
+}
+
+// diffLeaves reports the leaves that differ between old and cur. Unlike
+// Compare it descends into added and removed containers, so a watcher of
+// `db.host` hears about Delete("db").
+func diffLeaves(segs []pathSegment, old any, inOld bool, cur any, inNew bool, out *[]watchChange) {
+	emit := func(kind ChangeType, o, n any) {
+		*out = append(*out, watchChange{
+			segs:  segs,
+			event: ChangeEvent{Type: kind, Path: formatPath(segs), Old: deepClone(o), New: deepClone(n)},
+		})
+	}
+	if inOld && inNew && sameContainer(old, cur) {
+		return
+	}
+	oldKids, newKids := childrenOf(old), childrenOf(cur)
+	if !inOld {
+		oldKids = nil
+	}
+	if !inNew {
+		newKids = nil
+	}
+
+	if len(oldKids) == 0 && len(newKids) == 0 {
+		switch {
+		case inOld && inNew:
+			if valuesEqual(old, cur, false) {
+				return
+			}
+			kind := ChangeModified
+			if !sameKind(old, cur, false) {
+				kind = ChangeTypeChanged
+			}
+			emit(kind, old, cur)
+		case inOld:
+			emit(ChangeRemoved, old, nil)
+		case inNew:
+			emit(ChangeAdded, nil, cur)
+		}
+		return
+	}
+	// a leaf replaced by a container (or the reverse) is a removal plus additions
+	if inOld && len(oldKids) == 0 {
+		emit(ChangeRemoved, old, nil)
+	}
+	if inNew && len(newKids) == 0 {
+		defer emit(ChangeAdded, nil, cur)
+	}
+
+	type pair struct {
+		seg          pathSegment
+		old, cur     any
+		inOld, inNew bool
+	}
+	var pairs []pair
+	byKey := map[string]int{}
+	for _, c := range oldKids {
+		byKey[formatPath([]pathSegment{c.seg})] = len(pairs)
+		pairs = append(pairs, pair{seg: c.seg, old: c.value, inOld: true})
+	}
+	for _, c := range newKids {
+		key := formatPath([]pathSegment{c.seg})
+		if i, ok := byKey[key]; ok {
+			pairs[i].cur, pairs[i].inNew = c.value, true
+			continue
+		}
+		pairs = append(pairs, pair{seg: c.seg, cur: c.value, inNew: true})
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int {
+		if a.seg.kind == segmentIndex && b.seg.kind == segmentIndex {
+			return a.seg.index - b.seg.index
+		}
+		if a.seg.key < b.seg.key {
+			return -1
+		}
+		if a.seg.key > b.seg.key {
+			return 1
+		}
+		return 0
+	})
+	for _, p := range pairs {
+		child := append(segs[:len(segs):len(segs)], p.seg)
+		diffLeaves(child, p.old, p.inOld, p.cur, p.inNew, out)
+	}
+}
+
+// sameContainer reports whether a and b are the same map or slice, which
+// versions share for every subtree a write did not touch.
+func sameContainer(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		return ok && reflect.ValueOf(av).UnsafePointer() == reflect.ValueOf(bv).UnsafePointer()
+	case []any:
+		bv, ok := b.([]any)
+		return ok && len(av) == len(bv) && (len(av) == 0 || &av[0] == &bv[0])
+	}
+	return false
+}
+
+// ------------------- Delivery -------------------
+
+// watchHub is shared by every view of a SafeTreeMap, like its lock.
+type watchHub struct {
+	mu       sync.Mutex
+	watchers []*watcher
+	count    atomic.Int32 // len(watchers), read by writers without mu
+}
+
+func (h *watchHub) active() bool {
+	return h.count.Load() > 0
+}
+
+func (h *watchHub) add(w *watcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.watchers = append(h.watchers, w)
+	h.count.Store(int32(len(h.watchers)))
+}
+
+func (h *watchHub) remove(w *watcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.watchers = slices.DeleteFunc(h.watchers, func(x *watcher) bool { return x == w })
+	h.count.Store(int32(len(h.watchers)))
+}
+
+// publish queues changes for the matching watchers without blocking.
+func (h *watchHub) publish(changes []watchChange) {
+	if len(changes) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, w := range h.watchers {
+		w.push(changes)
+	}
+}
+
+type watcher struct {
+	pattern []pathSegment
+	deliver func(ChangeEvent)
+	hub     *watchHub
+
+	mu    sync.Mutex
+	queue []ChangeEvent
+	wake  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (w *watcher) push(changes []watchChange) {
+	w.mu.Lock()
+	n := len(w.queue)
+	for _, c := range changes {
+		if matchWatch(w.pattern, c.segs) {
+			w.queue = append(w.queue, c.event)
+		}
+	}
+	queued := len(w.queue) > n
+	w.mu.Unlock()
+	if queued {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *watcher) run() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.wake:
+		}
+		for {
+			w.mu.Lock()
+			if len(w.queue) == 0 {
+				w.mu.Unlock()
+				break
+			}
+			ev := w.queue[0]
+			w.queue = w.queue[1:]
+			w.mu.Unlock()
+
+			select {
+			case <-w.done:
+				return
+			default:
+			}
+			w.deliver(ev)
+		}
+	}
+}
+
+func (w *watcher) cancel() {
+	w.once.Do(func() {
+		w.hub.remove(w)
+		close(w.done)
+	})
+}
+
+// matchWatch reports whether pattern matches path or one of its ancestors.
+func matchWatch(pattern, path []pathSegment) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	seg := pattern[0]
+	if seg.kind == segmentRecursive {
+		for i := range path {
+			if matchWatch(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if seg.kind != segmentWildcard && seg.key != path[0].key {
+		return false
+	}
+	return matchWatch(pattern[1:], path[1:])
+}
@@ -1,208 +1,712 @@
 package goutils
 
 import (
+	"iter"
+	"net/netip"
+	"net/url"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// SafeTreeMap can be shared between goroutines. It keeps the tree as an
+// ImmutableTreeMap version behind an atomic pointer: reads load the current
+// version without locking, and writes copy the written path, then publish a
+// new version. Writers only wait for writers of overlapping paths, such as
+// `user` and `user.name`; writes to `user.name` and `user.email` do not
+// wait for each other. A write below a slice element locks the whole slice,
+// since splicing or growing the slice moves its elements, and whole-tree
+// writes wait for all writers.
+//
+// Nodes returned by Get, Query and GetPointer are live views that resolve
+// their path against the latest version on every call. Values handed out
+// (AsMap, AsSlice, iterators, Delete results) are snapshots.
+//
+// Get, GetPointer and Clone return a *SafeTreeMap behind TreeMapImpl; assert
+// it to reach Update, Watch, SetWithTTL and the other methods below.
 type SafeTreeMap struct {
-	mu *sync.RWMutex
-	tm TreeMapImpl
+	st   *safeState
+	path []pathSegment
+	err  error
+}
+
+type safeState struct {
+	root   atomic.Pointer[TreeMap]
+	shards [writeShards]sync.RWMutex // by top-level key
+	inner  [innerShards]sync.Mutex   // by the first two keys
+	hub    watchHub
+	ttl    ttlIndex
+}
+
+const (
+	writeShards = 16
+	innerShards = 64
+)
+
+// NewSafeTreeMap is NewSyncTreeMap returning the concrete type, for callers
+// of Update, Watch, SetWithTTL and the other SafeTreeMap methods.
+func NewSafeTreeMap(data ...any) *SafeTreeMap {
+	st := &safeState{}
+	st.root.Store(NewTreeMap(data...).(*TreeMap))
+	return &SafeTreeMap{st: st}
 }
 
 func NewSyncTreeMap(data ...any) TreeMapImpl {
-	mu := &sync.RWMutex{}
-	return &SafeTreeMap{mu: mu, tm: NewTreeMap(data...)}
+	return NewSafeTreeMap(data...)
+}
+
+// node resolves s against the current version, after evicting expired
+// paths whose locks are free.
+func (s *SafeTreeMap) node() *ImmutableTreeMap {
+	if s.err == nil {
+		s.st.expireDue()
+	}
+	return s.current()
+}
+
+func (s *SafeTreeMap) current() *ImmutableTreeMap {
+	if s.err != nil {
+		return &ImmutableTreeMap{tm: &TreeMap{err: s.err}}
+	}
+	return versionAt(s.st.root.Load(), s.path)
+}
+
+func versionAt(root *TreeMap, path []pathSegment) *ImmutableTreeMap {
+	return &ImmutableTreeMap{tm: root.getSegments(path).(*TreeMap)}
+}
+
+func (s *SafeTreeMap) view(segs []pathSegment) *SafeTreeMap {
+	return &SafeTreeMap{st: s.st, path: slices.Concat(s.path, segs)}
+}
+
+// Snapshot returns the current version of this node. It never changes and
+// costs O(1), see ImmutableTreeMap.
+func (s *SafeTreeMap) Snapshot() TreeMapImpl {
+	return s.node()
+}
+
+// ------------------- Write Path -------------------
+
+// commit runs change under the shard lock of the write scope of rel, a
+// path relative to s; see apply.
+func (s *SafeTreeMap) commit(rel []pathSegment, change func(node *ImmutableTreeMap) TreeMapImpl) TreeMapImpl {
+	if s.err != nil {
+		return &TreeMap{err: s.err}
+	}
+	scope, unlock := s.st.lockPath(s.abs(rel))
+	defer unlock()
+	return s.apply(scope, change)
+}
+
+// overwrite is commit for writes that replace the value at rel, which also
+// drops the TTLs set at or below it.
+func (s *SafeTreeMap) overwrite(rel []pathSegment, change func(node *ImmutableTreeMap) TreeMapImpl) TreeMapImpl {
+	if s.err != nil {
+		return &TreeMap{err: s.err}
+	}
+	full := s.abs(rel)
+	scope, unlock := s.st.lockPath(full)
+	defer unlock()
+	res := s.apply(scope, change)
+	if res.Err() == nil && isConcretePath(full) {
+		s.st.ttl.forget(full)
+	}
+	return res
+}
+
+// apply publishes change(node) as the new version, where node is s in the
+// current version. A writer of a disjoint scope may publish first; change
+// then runs again on its version, so change must read what it needs from
+// node. The lock of scope must be held: writers of overlapping scopes wait
+// for it, and no other write can reach the subtree at scope, since writes
+// below a slice lock the slice. Deadlines of the values change removed or
+// replaced are dropped under the same lock.
+func (s *SafeTreeMap) apply(scope []pathSegment, change func(node *ImmutableTreeMap) TreeMapImpl) TreeMapImpl {
+	for {
+		old := s.st.root.Load()
+		res := change(versionAt(old, s.path))
+		if res.Err() != nil {
+			return res
+		}
+		next := res.(*ImmutableTreeMap).rootTree()
+		if s.st.root.CompareAndSwap(old, next) {
+			s.st.ttl.dropChanged(scope, old.value, next.value)
+			s.st.publish(scope, old, next)
+			return res
+		}
+	}
+}
+
+// lockPath locks the write scope of path, an absolute path, and returns the
+// scope with the unlock func. The scope is checked again under the lock, as
+// a writer of an ancestor may have replaced a map on path in the meantime.
+func (st *safeState) lockPath(path []pathSegment) ([]pathSegment, func()) {
+	for {
+		scope := writeScope(st.root.Load().value, path)
+		unlock := st.lock(scope)
+		if len(writeScope(st.root.Load().value, path)) == len(scope) {
+			return scope, unlock
+		}
+		unlock()
+	}
+}
+
+// tryLockPath is lockPath without waiting; it reports false when a lock of
+// the scope is held.
+func (st *safeState) tryLockPath(path []pathSegment) ([]pathSegment, func(), bool) {
+	for {
+		scope := writeScope(st.root.Load().value, path)
+		unlock, ok := st.tryLock(scope)
+		if !ok {
+			return nil, nil, false
+		}
+		if len(writeScope(st.root.Load().value, path)) == len(scope) {
+			return scope, unlock, true
+		}
+		unlock()
+	}
+}
+
+// lock takes the shard locks of scope and returns the unlock func. A scope
+// with one segment locks its top-level shard; a deeper one shares that
+// shard with its siblings and locks the inner shard of its first two keys,
+// so only overlapping scopes exclude each other. An empty scope locks every
+// top-level shard.
+func (st *safeState) lock(scope []pathSegment) func() {
+	switch len(scope) {
+	case 0:
+		for i := range st.shards {
+			st.shards[i].Lock()
+		}
+		return func() {
+			for i := range st.shards {
+				st.shards[i].Unlock()
+			}
+		}
+	case 1:
+		mu := &st.shards[shardOf(scope[0].key)%writeShards]
+		mu.Lock()
+		return mu.Unlock
+	}
+	top := &st.shards[shardOf(scope[0].key)%writeShards]
+	top.RLock()
+	inner := &st.inner[shardOf(scope[0].key, scope[1].key)%innerShards]
+	inner.Lock()
+	return func() {
+		inner.Unlock()
+		top.RUnlock()
+	}
+}
+
+// tryLock is lock without waiting; it takes nothing when a lock of scope
+// is held.
+func (st *safeState) tryLock(scope []pathSegment) (func(), bool) {
+	switch len(scope) {
+	case 0:
+		for i := range st.shards {
+			if !st.shards[i].TryLock() {
+				for j := range i {
+					st.shards[j].Unlock()
+				}
+				return nil, false
+			}
+		}
+		return func() {
+			for i := range st.shards {
+				st.shards[i].Unlock()
+			}
+		}, true
+	case 1:
+		mu := &st.shards[shardOf(scope[0].key)%writeShards]
+		return mu.Unlock, mu.TryLock()
+	}
+	top := &st.shards[shardOf(scope[0].key)%writeShards]
+	if !top.TryRLock() {
+		return nil, false
+	}
+	inner := &st.inner[shardOf(scope[0].key, scope[1].key)%innerShards]
+	if !inner.TryLock() {
+		top.RUnlock()
+		return nil, false
+	}
+	return func() {
+		inner.Unlock()
+		top.RUnlock()
+	}, true
+}
+
+// shardOf hashes keys with FNV-1a.
+func shardOf(keys ...string) uint32 {
+	h := uint32(2166136261)
+	for _, key := range keys {
+		for i := 0; i < len(key); i++ {
+			h ^= uint32(key[i])
+			h *= 16777619
+		}
+		h ^= 0xff
+		h *= 16777619
+	}
+	return h
+}
+
+// writeScope returns the part of path that a write at path locks: path up
+// to the first segment that is not concrete or whose parent is not a map in
+// root. Elements of a slice move when a sibling is spliced or the slice
+// grows, so a write below a slice locks the whole slice, and a write below
+// a missing container locks its nearest existing map.
+func writeScope(root any, path []pathSegment) []pathSegment {
+	v := root
+	for i, seg := range path {
+		m, ok := v.(map[string]any)
+		if !ok || !seg.isConcrete() {
+			return path[:i]
+		}
+		v = m[seg.key]
+	}
+	return path
+}
+
+// abs returns rel as an absolute path.
+func (s *SafeTreeMap) abs(rel []pathSegment) []pathSegment {
+	return append(slices.Clone(s.path), rel...)
+}
+
+// isConcretePath reports whether path names one location, without `*`,
+// `..`, ranges, filters or an unquoted `-`.
+func isConcretePath(path []pathSegment) bool {
+	for _, seg := range path {
+		if !seg.isConcrete() || (seg.kind == segmentKey && !seg.quoted && seg.key == "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// relPath and relPointer parse for locking only; the write itself reports
+// syntax errors.
+func relPath(path string) []pathSegment {
+	segs, _ := parsePath(path)
+	return segs
+}
+
+func relPointer(pointer string) []pathSegment {
+	segs, _ := parsePointer(pointer)
+	return segs
 }
 
 // ------------------- Core -------------------
 func (s *SafeTreeMap) Get(path string) TreeMapImpl {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return &SafeTreeMap{mu: s.mu, tm: s.tm.Get(path)}
+	if s.err != nil {
+		return s
+	}
+	s.st.expireDue()
+	if v, ok := lookupValue(s.st.root.Load().value, s.path); !ok || v == nil {
+		return s
+	}
+	segs, err := parseConcretePath(path)
+	if err != nil {
+		return &SafeTreeMap{st: s.st, err: err}
+	}
+	return s.view(segs)
 }
 
 func (s *SafeTreeMap) IsDefined(path string) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.IsDefined(path)
+	return s.node().IsDefined(path)
 }
 
 func (s *SafeTreeMap) Exists() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.Exists()
+	return s.node().Exists()
 }
 
 func (s *SafeTreeMap) IsEmpty() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.IsEmpty()
+	return s.node().IsEmpty()
 }
 
 func (s *SafeTreeMap) Or(path string) TreeMapImpl {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.Or(path)
+	if s.err != nil {
+		return s
+	}
+	if n := s.node(); n.tm.err != nil || n.tm.value == nil {
+		return (&SafeTreeMap{st: s.st}).Get(path)
+	}
+	return s
 }
 
 func (s *SafeTreeMap) Set(path string, value any) TreeMapImpl {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.tm.Set(path, value)
+	if s.err != nil {
+		return &TreeMap{err: s.err}
+	}
+	segs, err := parseConcretePath(path)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	value = normalizeToDefault(value)
+	res := s.overwrite(segs, func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.setNormalized(segs, value)
+	})
+	if res.Err() != nil {
+		return res
+	}
 	return s
 }
 
+// Delete removes path and returns a snapshot of the removed value.
 func (s *SafeTreeMap) Delete(path string) TreeMapImpl {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.tm.Delete(path)
+	var removed TreeMapImpl
+	res := s.overwrite(relPath(path), func(node *ImmutableTreeMap) TreeMapImpl {
+		removed = node.Get(path)
+		return node.Delete(path)
+	})
+	if res.Err() != nil {
+		return res
+	}
+	return removed
 }
 
 func (s *SafeTreeMap) TryDelete(path string) TreeMapImpl {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.tm.TryDelete(path)
+	s.overwrite(relPath(path), func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.TryDelete(path)
+	})
+	return s
+}
+
+func (s *SafeTreeMap) Query(path string) ([]TreeMapImpl, error) {
+	matches, err := s.node().Query(path)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]TreeMapImpl, len(matches))
+	for i, m := range matches {
+		result[i] = &SafeTreeMap{st: s.st, path: m.(*ImmutableTreeMap).tm.path}
+	}
+	return result, nil
+}
+
+func (s *SafeTreeMap) SetAll(path string, value any) TreeMapImpl {
+	res := s.commit(relPath(path), func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.SetAll(path, value)
+	})
+	if res.Err() != nil {
+		return res
+	}
+	return s
+}
+
+func (s *SafeTreeMap) DeleteAll(path string) TreeMapImpl {
+	res := s.commit(relPath(path), func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.DeleteAll(path)
+	})
+	if res.Err() != nil {
+		return res
+	}
+	return s
+}
+
+func (s *SafeTreeMap) Path() string {
+	return s.node().Path()
+}
+
+// ------------------- JSON Pointer -------------------
+func (s *SafeTreeMap) GetPointer(pointer string) TreeMapImpl {
+	segs, err := parsePointer(pointer)
+	if err != nil {
+		return &SafeTreeMap{st: s.st, err: err}
+	}
+	return s.view(segs)
+}
+
+func (s *SafeTreeMap) SetPointer(pointer string, value any) TreeMapImpl {
+	res := s.overwrite(relPointer(pointer), func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.SetPointer(pointer, value)
+	})
+	if res.Err() != nil {
+		return res
+	}
+	return s
+}
+
+// DeletePointer removes pointer and returns a snapshot of the removed value.
+func (s *SafeTreeMap) DeletePointer(pointer string) TreeMapImpl {
+	var removed TreeMapImpl
+	res := s.overwrite(relPointer(pointer), func(node *ImmutableTreeMap) TreeMapImpl {
+		removed = node.GetPointer(pointer)
+		return node.DeletePointer(pointer)
+	})
+	if res.Err() != nil {
+		return res
+	}
+	return removed
+}
+
+func (s *SafeTreeMap) Pointer() string {
+	return s.node().Pointer()
+}
+
+func (s *SafeTreeMap) ApplyPatch(ops []PatchOperation) TreeMapImpl {
+	res := s.commit(nil, func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.ApplyPatch(ops)
+	})
+	if res.Err() != nil {
+		return res
+	}
+	return s
+}
+
+func (s *SafeTreeMap) Merge(other TreeMapImpl, opts ...MergeOptions) TreeMapImpl {
+	res := s.commit(nil, func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.Merge(other, opts...)
+	})
+	if res.Err() != nil {
+		return res
+	}
 	return s
 }
 
+func (s *SafeTreeMap) Validate(schema TreeMapImpl) error {
+	return s.node().Validate(schema)
+}
+
+// ------------------- Iteration -------------------
+// Iterators walk the version that was current when the loop started and
+// hold no lock, so the loop body may write to this SafeTreeMap.
+
+func (s *SafeTreeMap) All() iter.Seq2[string, TreeMapImpl] {
+	return func(yield func(string, TreeMapImpl) bool) {
+		s.node().All()(yield)
+	}
+}
+
+func (s *SafeTreeMap) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		s.node().Keys()(yield)
+	}
+}
+
+func (s *SafeTreeMap) Entries() iter.Seq2[string, TreeMapImpl] {
+	return func(yield func(string, TreeMapImpl) bool) {
+		s.node().Entries()(yield)
+	}
+}
+
+func (s *SafeTreeMap) Len() int {
+	return s.node().Len()
+}
+
+func (s *SafeTreeMap) Walk(fn WalkFunc) error {
+	return s.node().Walk(fn)
+}
+
+func (s *SafeTreeMap) Flatten(opts ...FlattenOptions) DefaultMap {
+	return s.node().Flatten(opts...)
+}
+
+// Clone returns an independent SafeTreeMap rooted at this node, with the
+// TTLs set below it. It shares the current version instead of copying it.
 func (s *SafeTreeMap) Clone() TreeMapImpl {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return &SafeTreeMap{mu: &sync.RWMutex{}, tm: s.tm.Clone()}
+	n := s.node()
+	if n.tm.err != nil {
+		return &SafeTreeMap{st: s.st, err: n.tm.err}
+	}
+	root := &TreeMap{value: n.tm.value, conv: n.rootTree().conv}
+	root.root = root
+	st := &safeState{}
+	st.root.Store(root)
+	s.st.ttl.copyUnder(s.path, &st.ttl)
+	return &SafeTreeMap{st: st}
 }
 
 func (s *SafeTreeMap) ToJsonString(pretty bool) string {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.ToJsonString(pretty)
+	return s.node().ToJsonString(pretty)
 }
 
 func (s *SafeTreeMap) AsMap() (DefaultMap, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsMap()
+	return s.node().AsMap()
 }
 
 func (s *SafeTreeMap) AsSlice() ([]TreeMapImpl, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsSlice()
+	return s.node().AsSlice()
 }
 
 // ------------------- Value Conversions -------------------
 func (s *SafeTreeMap) AsString() (string, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsString()
+	return s.node().AsString()
 }
 
 func (s *SafeTreeMap) AsInt() (int64, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsInt()
+	return s.node().AsInt()
 }
 
 func (s *SafeTreeMap) AsFloat() (float64, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsFloat()
+	return s.node().AsFloat()
 }
 
 func (s *SafeTreeMap) AsBool() (bool, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsBool()
+	return s.node().AsBool()
 }
 
 func (s *SafeTreeMap) AsAny() (any, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsAny()
+	return s.node().AsAny()
 }
 
 // ------------------- Struct / Slice Conversions -------------------
 func (s *SafeTreeMap) AsSliceOf(target []any) error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsSliceOf(target)
+	return s.node().AsSliceOf(target)
 }
 
 func (s *SafeTreeMap) AsStruct(target any) error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsStruct(target)
+	return s.node().AsStruct(target)
+}
+
+func (s *SafeTreeMap) WithConverter(c *Converter) TreeMapImpl {
+	s.commit(nil, func(node *ImmutableTreeMap) TreeMapImpl {
+		return node.WithConverter(c)
+	})
+	return s
+}
+
+func (s *SafeTreeMap) converter() *Converter {
+	return s.node().converter()
+}
+
+func (s *SafeTreeMap) Bind(target any) error {
+	return s.node().Bind(target)
 }
 
 // ------------------- Default Fallbacks -------------------
 func (s *SafeTreeMap) AsStringOr(def string) string {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsStringOr(def)
+	return s.node().AsStringOr(def)
 }
 
 func (s *SafeTreeMap) AsIntOr(def int64) int64 {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsIntOr(def)
+	return s.node().AsIntOr(def)
 }
 
 func (s *SafeTreeMap) AsFloatOr(def float64) float64 {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsFloatOr(def)
+	return s.node().AsFloatOr(def)
 }
 
 func (s *SafeTreeMap) AsBoolOr(def bool) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsBoolOr(def)
+	return s.node().AsBoolOr(def)
 }
 
 func (s *SafeTreeMap) AsAnyOr(def any) any {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsAnyOr(def)
+	return s.node().AsAnyOr(def)
 }
 
 // ------------------- Slice Helpers -------------------
 func (s *SafeTreeMap) AsStrSlice() []string {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsStrSlice()
+	return s.node().AsStrSlice()
 }
 
 func (s *SafeTreeMap) AsIntSlice() []int64 {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsIntSlice()
+	return s.node().AsIntSlice()
 }
 
 func (s *SafeTreeMap) AsBoolSlice() []bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsBoolSlice()
+	return s.node().AsBoolSlice()
 }
 
 func (s *SafeTreeMap) AsAnySlice() []any {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.AsAnySlice()
+	return s.node().AsAnySlice()
+}
+
+// ------------------- Semantic Accessors -------------------
+
+func (s *SafeTreeMap) AsTime(layouts ...string) (time.Time, error) {
+	return s.node().AsTime(layouts...)
+}
+
+func (s *SafeTreeMap) AsDuration() (time.Duration, error) {
+	return s.node().AsDuration()
+}
+
+func (s *SafeTreeMap) AsURL() (*url.URL, error) {
+	return s.node().AsURL()
+}
+
+func (s *SafeTreeMap) AsIP() (netip.Addr, error) {
+	return s.node().AsIP()
+}
+
+func (s *SafeTreeMap) AsCIDR() (netip.Prefix, error) {
+	return s.node().AsCIDR()
+}
+
+func (s *SafeTreeMap) AsUUID() (uuid.UUID, error) {
+	return s.node().AsUUID()
+}
+
+func (s *SafeTreeMap) AsByteSize() (int64, error) {
+	return s.node().AsByteSize()
+}
+
+func (s *SafeTreeMap) AsTimeOr(def time.Time, layouts ...string) time.Time {
+	return s.node().AsTimeOr(def, layouts...)
+}
+
+func (s *SafeTreeMap) AsDurationOr(def time.Duration) time.Duration {
+	return s.node().AsDurationOr(def)
+}
+
+func (s *SafeTreeMap) AsURLOr(def *url.URL) *url.URL {
+	return s.node().AsURLOr(def)
+}
+
+func (s *SafeTreeMap) AsIPOr(def netip.Addr) netip.Addr {
+	return s.node().AsIPOr(def)
+}
+
+func (s *SafeTreeMap) AsCIDROr(def netip.Prefix) netip.Prefix {
+	return s.node().AsCIDROr(def)
+}
+
+func (s *SafeTreeMap) AsUUIDOr(def uuid.UUID) uuid.UUID {
+	return s.node().AsUUIDOr(def)
+}
+
+func (s *SafeTreeMap) AsByteSizeOr(def int64) int64 {
+	return s.node().AsByteSizeOr(def)
+}
+
+func (s *SafeTreeMap) AsTimeSlice(layouts ...string) []time.Time {
+	return s.node().AsTimeSlice(layouts...)
+}
+
+func (s *SafeTreeMap) AsDurationSlice() []time.Duration {
+	return s.node().AsDurationSlice()
+}
+
+func (s *SafeTreeMap) AsURLSlice() []*url.URL {
+	return s.node().AsURLSlice()
+}
+
+func (s *SafeTreeMap) AsIPSlice() []netip.Addr {
+	return s.node().AsIPSlice()
+}
+
+func (s *SafeTreeMap) AsCIDRSlice() []netip.Prefix {
+	return s.node().AsCIDRSlice()
+}
+
+func (s *SafeTreeMap) AsUUIDSlice() []uuid.UUID {
+	return s.node().AsUUIDSlice()
+}
+
+func (s *SafeTreeMap) AsByteSizeSlice() []int64 {
+	return s.node().AsByteSizeSlice()
 }
 
 func (s *SafeTreeMap) getValue() any {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.getValue()
+	return s.node().getValue()
 }
 
-func (s *SafeTreeMap) getError() error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.getError()
+func (s *SafeTreeMap) Err() error {
+	return s.node().Err()
 }
 
 func (s *SafeTreeMap) getRoot() TreeMapImpl {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.tm.getRoot()
+	return &SafeTreeMap{st: s.st}
 }
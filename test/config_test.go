@@ -0,0 +1,69 @@
+package test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nitsugaro/go-utils/config"
+)
+
+// ------------------- Config Tests -------------------
+
+func TestConfig_Layers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte(`{"db": {"host": "file-host", "user": "app"}, "debug": false}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_DB__HOST", "env-host")
+	t.Setenv("APP_LOG__LEVEL", "warn")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("debug", false, "")
+	fs.Int("db.port", 5432, "")
+	if err := fs.Parse([]string{"-debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.New()
+	cfg.AddDefaults(map[string]any{"db": map[string]any{"host": "localhost", "port": 5432}, "log": map[string]any{"level": "info"}})
+	if err := cfg.AddFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.AddEnv("APP"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.AddFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		path, value, source string
+	}{
+		{"db.host", "env-host", "env"},
+		{"db.user", "app", "file:" + file},
+		{"db.port", "5432", "defaults"},
+		{"log.level", "warn", "env"},
+		{"debug", "true", "flags"},
+	}
+	for _, c := range cases {
+		if got := cfg.Get(c.path).AsStringOr(""); got != c.value {
+			t.Errorf("%s: expected %q, got %q", c.path, c.value, got)
+		}
+		if got := cfg.Source(c.path); got != c.source {
+			t.Errorf("%s: expected source %q, got %q", c.path, c.source, got)
+		}
+	}
+
+	if cfg.Source("missing") != "" {
+		t.Errorf("expected no source for a missing path")
+	}
+	if want := []string{"defaults", "file:" + file, "env", "flags"}; !slices.Equal(cfg.Layers(), want) {
+		t.Errorf("expected layers %v, got %v", want, cfg.Layers())
+	}
+	if err := cfg.AddFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("expected error for a missing file")
+	}
+}
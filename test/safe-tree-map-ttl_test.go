@@ -0,0 +1,171 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- TTL Tests -------------------
+
+func TestSafeTreeMap_SetWithTTL(t *testing.T) {
+	cache := goutils.NewSafeTreeMap()
+
+	var (
+		mu      sync.Mutex
+		evicted = map[string]any{}
+	)
+	cancel := cache.OnEvict(func(path string, value goutils.TreeMapImpl) {
+		mu.Lock()
+		defer mu.Unlock()
+		evicted[path] = value.Get("token").AsStringOr("")
+	})
+	defer cancel()
+
+	cache.SetWithTTL("tokens.acme", map[string]any{"token": "a1"}, 20*time.Millisecond)
+	cache.Get("tokens").(*goutils.SafeTreeMap).SetWithTTL("globex", map[string]any{"token": "g1"}, time.Hour)
+	cache.SetWithTTL("plain", 1, 20*time.Millisecond)
+	cache.Set("plain", 2)
+	cache.SetWithTTL("session", map[string]any{"user": "x"}, 20*time.Millisecond)
+	cache.Set("session.seen", true)
+
+	if left, ok := cache.TTL("tokens.globex"); !ok || left < 59*time.Minute {
+		t.Errorf("expected about an hour left, got %v (%v)", left, ok)
+	}
+	if _, ok := cache.TTL("plain"); ok {
+		t.Errorf("expected Set to drop the deadline")
+	}
+	if _, ok := cache.TTL("session"); !ok {
+		t.Errorf("expected writes below the path to keep its deadline")
+	}
+	if res := cache.SetWithTTL("list.-", 1, time.Second); res.Exists() {
+		t.Errorf("expected append paths to be refused")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if cache.IsDefined("tokens.acme") || cache.IsDefined("session") {
+		t.Errorf("expected expired paths to be gone, got %s", cache.ToJsonString(false))
+	}
+	if !cache.IsDefined("tokens.globex") || cache.Get("plain").AsIntOr(0) != 2 {
+		t.Errorf("expected other paths to stay, got %s", cache.ToJsonString(false))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if evicted["tokens.acme"] != "a1" || len(evicted) != 2 {
+		t.Errorf("unexpected evictions %v", evicted)
+	}
+}
+
+func TestSafeTreeMap_SetWithTTLSlices(t *testing.T) {
+	cache := goutils.NewSafeTreeMap(map[string]any{"items": []any{"a", "b"}})
+
+	for _, path := range []string{"items.1", "items[0].x", "fresh.0"} {
+		if cache.SetWithTTL(path, "x", 10*time.Millisecond).Err() == nil {
+			t.Errorf("expected %s to be refused", path)
+		}
+	}
+	if got := cache.ToJsonString(false); got != `{"items":["a","b"]}` {
+		t.Errorf("expected refused writes to leave the tree alone, got %s", got)
+	}
+
+	cache.Delete("items.0")
+	time.Sleep(20 * time.Millisecond)
+	if got := cache.Get("items").ToJsonString(false); got != `["b"]` {
+		t.Errorf("expected the moved element to stay, got %s", got)
+	}
+}
+
+func TestSafeTreeMap_WritesDropDeadlines(t *testing.T) {
+	cache := goutils.NewSafeTreeMap()
+	for _, path := range []string{"merged", "patched", "all", "counter", "swapped", "updated", "kept", "grown"} {
+		cache.SetWithTTL(path, 1, 20*time.Millisecond)
+	}
+	cache.SetWithTTL("grown", map[string]any{"a": 1}, 20*time.Millisecond)
+
+	cache.Merge(goutils.NewTreeMap(map[string]any{"merged": 2, "other": true}))
+	cache.ApplyPatch([]goutils.PatchOperation{{Op: "replace", Path: "/patched", Value: 2}})
+	cache.SetAll("all", 2)
+	cache.Increment("counter", 1)
+	cache.CompareAndSet("swapped", 1, 2)
+	cache.Update(func(tx goutils.TreeMapImpl) error {
+		return tx.Set("updated", 2).Err()
+	})
+	cache.Set("grown.b", 2)
+
+	for _, path := range []string{"merged", "patched", "all", "counter", "swapped", "updated"} {
+		if _, ok := cache.TTL(path); ok {
+			t.Errorf("expected the write to %s to drop its deadline", path)
+		}
+	}
+	for _, path := range []string{"kept", "grown"} {
+		if _, ok := cache.TTL(path); !ok {
+			t.Errorf("expected %s to keep its deadline", path)
+		}
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if got := cache.ToJsonString(false); got != `{"all":2,"counter":2,"merged":2,"other":true,"patched":2,"swapped":2,"updated":2}` {
+		t.Errorf("expected only unwritten paths to expire, got %s", got)
+	}
+}
+
+func TestSafeTreeMap_ReadInsideUpdate(t *testing.T) {
+	cache := goutils.NewSafeTreeMap()
+	cache.SetWithTTL("session", "x", 5*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cache.Update(func(tx goutils.TreeMapImpl) error {
+			// the deadline passes while Update holds the root lock
+			time.Sleep(10 * time.Millisecond)
+			cache.Get("session").AsStringOr("")
+			return tx.Set("seen", true).Err()
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a read inside Update not to wait for its locks")
+	}
+
+	if cache.IsDefined("session") || !cache.Get("seen").AsBoolOr(false) {
+		t.Errorf("expected the next read to evict, got %s", cache.ToJsonString(false))
+	}
+}
+
+func TestSafeTreeMap_Janitor(t *testing.T) {
+	cache := goutils.NewSafeTreeMap()
+	cache.SetWithTTL("k", "v", 10*time.Millisecond)
+
+	clone := cache.Clone().(*goutils.SafeTreeMap)
+	if _, ok := clone.TTL("k"); !ok {
+		t.Errorf("expected Clone to keep deadlines")
+	}
+
+	evicted := make(chan string, 1)
+	cache.OnEvict(func(path string, _ goutils.TreeMapImpl) { evicted <- path })
+	events, cancel := cache.Watch("k")
+	defer cancel()
+
+	stop := cache.StartJanitor(5 * time.Millisecond)
+	defer stop()
+
+	select {
+	case path := <-evicted:
+		if path != "k" {
+			t.Errorf("unexpected eviction %q", path)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the janitor to evict without reads")
+	}
+	if ev := nextEvent(t, events); ev.Type != goutils.ChangeRemoved || ev.Path != "k" {
+		t.Errorf("expected a removal event, got %v", ev)
+	}
+	stop()
+}
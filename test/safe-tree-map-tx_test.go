@@ -0,0 +1,142 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Transaction Tests -------------------
+
+func TestSafeTreeMap_Update(t *testing.T) {
+	m := goutils.NewSafeTreeMap(map[string]any{
+		"accounts": map[string]any{"a": 100, "b": 0},
+	})
+	accounts := m.Get("accounts").(*goutils.SafeTreeMap)
+
+	transfer := func(amount int64) error {
+		return accounts.Update(func(tx goutils.TreeMapImpl) error {
+			a := tx.Get("a").AsIntOr(0)
+			tx.Set("a", a-amount)
+			tx.Set("b", tx.Get("b").AsIntOr(0)+amount)
+			if a < amount {
+				return errors.New("insufficient funds")
+			}
+			return nil
+		})
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			transfer(10)
+		}()
+	}
+	wg.Wait()
+	if a, b := m.Get("accounts.a").AsIntOr(-1), m.Get("accounts.b").AsIntOr(-1); a != 0 || b != 100 {
+		t.Fatalf("expected a=0 b=100, got a=%d b=%d", a, b)
+	}
+
+	if err := transfer(10); err == nil || err.Error() != "insufficient funds" {
+		t.Errorf("expected the error from fn, got %v", err)
+	}
+	if a, b := m.Get("accounts.a").AsIntOr(-1), m.Get("accounts.b").AsIntOr(-1); a != 0 || b != 100 {
+		t.Errorf("expected rollback, got a=%d b=%d", a, b)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected the panic to propagate")
+			}
+		}()
+		m.Update(func(tx goutils.TreeMapImpl) error {
+			tx.Delete("accounts")
+			panic("boom")
+		})
+	}()
+	if !m.IsDefined("accounts.b") {
+		t.Errorf("expected rollback after a panic")
+	}
+}
+
+func TestSafeTreeMap_CompareAndSet(t *testing.T) {
+	m := goutils.NewSafeTreeMap(map[string]any{"state": "pending", "n": 1.0})
+
+	if m.CompareAndSet("state", "done", "failed") {
+		t.Errorf("expected a mismatch to refuse the write")
+	}
+	if !m.CompareAndSet("state", "pending", "done") || m.Get("state").AsStringOr("") != "done" {
+		t.Errorf("expected the swap to happen")
+	}
+	if !m.CompareAndSet("n", 1, 2) {
+		t.Errorf("expected numbers to compare by value")
+	}
+	if !m.CompareAndSet("lock.owner", nil, "w1") || m.CompareAndSet("lock.owner", nil, "w2") {
+		t.Errorf("expected nil to match only a missing path")
+	}
+	if m.CompareAndSet("state.x", nil, 1) {
+		t.Errorf("expected a path through a scalar to fail")
+	}
+}
+
+func TestSafeTreeMap_Increment(t *testing.T) {
+	m := goutils.NewSafeTreeMap(map[string]any{"ratio": 1.5, "name": "x"})
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Increment("stats.hits", 2)
+		}()
+	}
+	wg.Wait()
+	if n := m.Get("stats.hits").AsIntOr(0); n != 100 {
+		t.Errorf("expected 100 hits, got %d", n)
+	}
+	if n, err := m.Increment("stats.hits", -1); err != nil || n != 99 {
+		t.Errorf("expected 99, got %d (%v)", n, err)
+	}
+	if _, err := m.Increment("ratio", 1); !errors.Is(err, goutils.ErrLossyConversion) {
+		t.Errorf("expected fractional numbers to be refused, got %v", err)
+	}
+	var mismatch *goutils.ErrTypeMismatch
+	if _, err := m.Increment("name", 1); !errors.As(err, &mismatch) || mismatch.Path != "name" {
+		t.Errorf("expected type mismatch, got %v", err)
+	}
+	m.Set("big", int64(1<<62))
+	if _, err := m.Increment("big", 1<<62); !errors.Is(err, goutils.ErrOutOfRange) {
+		t.Errorf("expected overflow error, got %v", err)
+	}
+}
+
+func TestSafeTreeMap_SliceWritesSerialize(t *testing.T) {
+	// every run must end in the result of one of the two serial orders
+	for range 2000 {
+		m := goutils.NewSafeTreeMap(map[string]any{"items": []any{0, 10, 11}})
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			m.Delete("items.1")
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			m.Increment("items.2", 1)
+		}()
+		close(start)
+		wg.Wait()
+		got := m.Get("items").ToJsonString(false)
+		if got != "[0,11,1]" && got != "[0,12]" {
+			t.Fatalf("expected [0,11,1] or [0,12], got %s", got)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Watch Tests -------------------
+
+func nextEvent(t *testing.T, events <-chan goutils.ChangeEvent) goutils.ChangeEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for an event")
+		return goutils.ChangeEvent{}
+	}
+}
+
+func TestSafeTreeMap_Watch(t *testing.T) {
+	m := goutils.NewSafeTreeMap(map[string]any{
+		"db":  map[string]any{"host": "localhost", "port": 5432},
+		"log": map[string]any{"level": "info"},
+	})
+
+	events, cancel := m.Watch("db")
+	m.Set("log.level", "debug")
+	m.Set("db.port", 5433)
+	m.Set("db.port", 5433)
+	m.Delete("db")
+
+	ev := nextEvent(t, events)
+	if ev.Type != goutils.ChangeModified || ev.Path != "db.port" || ev.Old != 5432 || ev.New != 5433 {
+		t.Errorf("unexpected event %v", ev)
+	}
+	if ev := nextEvent(t, events); ev.Type != goutils.ChangeRemoved || ev.Path != "db.host" || ev.Old != "localhost" {
+		t.Errorf("expected removal of db.host, got %v", ev)
+	}
+	if ev := nextEvent(t, events); ev.Path != "db.port" || ev.New != nil {
+		t.Errorf("expected removal of db.port, got %v", ev)
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Errorf("expected the channel to be closed after cancel")
+	}
+	m.Set("db.host", "other")
+
+	if _, cancel := m.Watch("items[?(@.x)]"); cancel == nil {
+		t.Errorf("expected a cancel func for invalid patterns")
+	}
+}
+
+func TestSafeTreeMap_WatchPatterns(t *testing.T) {
+	m := goutils.NewSafeTreeMap(map[string]any{
+		"users": []any{map[string]any{"email": "a@x.io"}},
+	})
+
+	emails, cancel := m.Watch("users[*].email")
+	defer cancel()
+	ids, cancelIDs := m.Get("users").(*goutils.SafeTreeMap).Watch("..id")
+	defer cancelIDs()
+
+	m.Set("users.-", map[string]any{"email": "b@x.io", "id": 2})
+	m.Set("users[0].id", 1)
+	m.Set("users[0].email", "c@x.io")
+
+	if ev := nextEvent(t, emails); ev.Type != goutils.ChangeAdded || ev.Path != "users[1].email" || ev.New != "b@x.io" {
+		t.Errorf("unexpected event %v", ev)
+	}
+	if ev := nextEvent(t, emails); ev.Path != "users[0].email" || ev.Old != "a@x.io" {
+		t.Errorf("unexpected event %v", ev)
+	}
+	if ev := nextEvent(t, ids); ev.Path != "users[1].id" {
+		t.Errorf("unexpected event %v", ev)
+	}
+	if ev := nextEvent(t, ids); ev.Path != "users[0].id" || ev.New != 1 {
+		t.Errorf("unexpected event %v", ev)
+	}
+}
+
+func TestSafeTreeMap_WatchSlices(t *testing.T) {
+	m := goutils.NewSafeTreeMap(map[string]any{
+		"items": []any{"a", "b", "c"},
+		"tags":  []any{"x"},
+	})
+
+	events, cancel := m.Watch("*")
+	defer cancel()
+
+	m.Delete("items.1")
+	if ev := nextEvent(t, events); ev.Type != goutils.ChangeModified || ev.Path != "items[1]" || ev.Old != "b" || ev.New != "c" {
+		t.Errorf("expected items[1] to shift, got %v", ev)
+	}
+	if ev := nextEvent(t, events); ev.Type != goutils.ChangeRemoved || ev.Path != "items[2]" || ev.Old != "c" {
+		t.Errorf("expected removal of the old last index, got %v", ev)
+	}
+
+	m.Set("tags.3", "y")
+	for _, want := range []string{"tags[1]", "tags[2]", "tags[3]"} {
+		if ev := nextEvent(t, events); ev.Type != goutils.ChangeAdded || ev.Path != want {
+			t.Errorf("expected %s to be added, got %v", want, ev)
+		}
+	}
+
+	m.Set("empty", map[string]any{})
+	nextEvent(t, events)
+	before := m.Snapshot()
+	m.Set("empty", 1)
+	ev := nextEvent(t, events)
+	old, ok := ev.Old.(map[string]any)
+	if !ok {
+		t.Fatalf("expected the old empty map, got %v", ev)
+	}
+	old["x"] = 1
+	if before.IsDefined("empty.x") {
+		t.Errorf("expected Old to be a detached copy")
+	}
+}
+
+func TestSafeTreeMap_OnChange(t *testing.T) {
+	m := goutils.NewSafeTreeMap(map[string]any{"n": 0})
+
+	var (
+		mu   sync.Mutex
+		seen []any
+		done = make(chan struct{})
+	)
+	cancel := m.OnChange("n", func(ev goutils.ChangeEvent) {
+		// callbacks run outside the lock, so reading the tree must not block
+		_ = m.Get("n").AsIntOr(0)
+		mu.Lock()
+		seen = append(seen, ev.New)
+		if len(seen) == 100 {
+			close(done)
+		}
+		mu.Unlock()
+	})
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Set("n", i)
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for 100 events")
+	}
+	final := m.Get("n").AsAnyOr(nil)
+	mu.Lock()
+	defer mu.Unlock()
+	if seen[len(seen)-1] != final {
+		t.Errorf("expected the last event to match the committed value %v, got %v", final, seen[len(seen)-1])
+	}
+}
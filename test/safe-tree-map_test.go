@@ -0,0 +1,95 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- SafeTreeMap Concurrency Tests -------------------
+
+func TestSafeTreeMap_SnapshotsAreDetached(t *testing.T) {
+	safe := goutils.NewSafeTreeMap(map[string]any{
+		"db":    map[string]any{"host": "localhost"},
+		"items": []any{1, 2},
+	})
+
+	m, _ := safe.Get("db").AsMap()
+	m["host"] = "mutated"
+	items, _ := safe.Get("items").AsSlice()
+	items[0].Set("", 9)
+	if safe.Get("db.host").AsStringOr("") != "localhost" || safe.Get("items[0]").AsIntOr(0) != 1 {
+		t.Errorf("expected returned values not to write through, got %s", safe.ToJsonString(false))
+	}
+
+	snap := safe.Snapshot()
+	a, _ := goutils.GetAs[any](safe, "db")
+	a.(map[string]any)["host"] = "X"
+	var bound struct {
+		DB map[string]any `tm:"db"`
+	}
+	safe.Bind(&bound)
+	bound.DB["host"] = "Y"
+	if safe.Get("db.host").AsStringOr("") != "localhost" || snap.Get("db.host").AsStringOr("") != "localhost" {
+		t.Errorf("expected GetAs and Bind results not to alias the tree, got %s", safe.ToJsonString(false))
+	}
+
+	snap = safe.Snapshot()
+	db := safe.Get("db")
+	safe.Set("db.host", "replica")
+	if snap.Get("db.host").AsStringOr("") != "localhost" {
+		t.Errorf("expected snapshots to keep their version")
+	}
+	if db.Get("host").AsStringOr("") != "replica" {
+		t.Errorf("expected views to follow new versions")
+	}
+
+	removed := safe.Delete("db")
+	if removed.Get("host").AsStringOr("") != "replica" || db.Exists() {
+		t.Errorf("expected Delete to return the removed value and views to see the removal")
+	}
+
+	for path := range safe.All() {
+		safe.Set("seen."+path, true)
+	}
+	if !safe.IsDefined("seen") {
+		t.Errorf("expected writes inside a loop not to block")
+	}
+}
+
+func TestSafeTreeMap_ConcurrentShards(t *testing.T) {
+	safe := goutils.NewSyncTreeMap()
+
+	var wg sync.WaitGroup
+	for shard := 0; shard < 8; shard++ {
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				safe.Set(fmt.Sprintf("s%d.k%d", shard, i), i)
+			}()
+			go func() {
+				defer wg.Done()
+				_ = safe.Get(fmt.Sprintf("s%d", shard)).Len()
+				_, _ = safe.AsMap()
+			}()
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		safe.Merge(goutils.NewTreeMap(map[string]any{"merged": true}))
+	}()
+	wg.Wait()
+
+	for shard := 0; shard < 8; shard++ {
+		if n := safe.Get(fmt.Sprintf("s%d", shard)).Len(); n != 50 {
+			t.Errorf("expected 50 keys in shard s%d, got %d", shard, n)
+		}
+	}
+	if !safe.Get("merged").AsBoolOr(false) {
+		t.Errorf("expected the whole-tree write to land")
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Bind Tests -------------------
+
+type bindDB struct {
+	Host string `tm:"host,required,default=localhost"`
+	Port uint16 `tm:"port,default=5432"`
+}
+
+type bindConfig struct {
+	Primary bindDB         `tm:"db.primary"`
+	Replica *bindDB        `tm:"db.replica"`
+	Timeout time.Duration  `tm:"http.timeout"`
+	Tags    []string       `tm:"tags"`
+	First   string         `tm:"servers[0].name"`
+	Labels  map[string]int `tm:"labels"`
+	Name    string         `tm:"name,required"`
+	Note    string         `tm:"note,default=a, b"`
+	Ignored string
+}
+
+func TestTreeMap_Bind(t *testing.T) {
+	m := goutils.NewSyncTreeMap(map[string]any{
+		"db": map[string]any{
+			"primary": map[string]any{"host": "db1", "port": "6543"},
+			"replica": map[string]any{},
+		},
+		"http":    map[string]any{"timeout": "5s"},
+		"tags":    []any{"a", "b"},
+		"servers": []any{map[string]any{"name": "web"}},
+		"labels":  map[string]any{"x": 1},
+		"name":    "svc",
+		"Ignored": "nope",
+	})
+
+	var cfg bindConfig
+	if err := m.Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Primary.Host != "db1" || cfg.Primary.Port != 6543 {
+		t.Errorf("unexpected primary %+v", cfg.Primary)
+	}
+	if cfg.Replica == nil || cfg.Replica.Host != "localhost" || cfg.Replica.Port != 5432 {
+		t.Errorf("expected replica defaults, got %+v", cfg.Replica)
+	}
+	if cfg.Timeout != 5*time.Second || len(cfg.Tags) != 2 || cfg.First != "web" || cfg.Labels["x"] != 1 {
+		t.Errorf("unexpected bound values %+v", cfg)
+	}
+	if cfg.Name != "svc" || cfg.Note != "a, b" || cfg.Ignored != "" {
+		t.Errorf("unexpected name/note/ignored %+v", cfg)
+	}
+}
+
+func TestTreeMap_BindErrors(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"db":   map[string]any{"primary": map[string]any{"port": 70000}},
+		"tags": "not-a-list",
+	})
+
+	var cfg bindConfig
+	err := m.Bind(&cfg)
+
+	var berr *goutils.BindError
+	if !errors.As(err, &berr) {
+		t.Fatalf("expected *BindError, got %v", err)
+	}
+	paths := make([]string, len(berr.Fields))
+	for i, f := range berr.Fields {
+		paths[i] = f.Path
+	}
+	if got := strings.Join(paths, ","); got != "db.primary.port,tags,name" {
+		t.Errorf("unexpected failing paths %s", got)
+	}
+	if !errors.Is(err, goutils.ErrRequired) || !errors.Is(err, goutils.ErrOutOfRange) {
+		t.Errorf("expected ErrRequired and ErrOutOfRange in %v", err)
+	}
+	if berr.Fields[0].Field != "Primary.Port" {
+		t.Errorf("expected nested field name, got %s", berr.Fields[0].Field)
+	}
+
+	if err := m.Bind(cfg); err == nil {
+		t.Errorf("expected error for a non-pointer target")
+	}
+}
@@ -0,0 +1,177 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Coercion Tests -------------------
+
+type coerceLevel int
+
+const (
+	levelLow coerceLevel = iota + 1
+	levelHigh
+)
+
+type coerceCents int64
+
+func TestTreeMap_CoercionModes(t *testing.T) {
+	// the trees below only read data, so they can share it
+	data := map[string]any{
+		"ratio": 3.7,
+		"flag":  1,
+		"yes":   " Yes ",
+		"small": float32(1.5),
+		"count": int32(7),
+		"huge":  int64(1<<53 + 1),
+		"price": "12.34",
+	}
+
+	def := goutils.NewTreeMap(data)
+	if n, err := def.Get("ratio").AsInt(); err != nil || n != 3 {
+		t.Errorf("expected the default mode to truncate floats, got %d (%v)", n, err)
+	}
+	if _, err := def.Get("flag").AsBool(); err == nil {
+		t.Errorf("expected the default mode to refuse numeric booleans")
+	}
+	if _, err := def.Get("yes").AsBool(); err == nil {
+		t.Errorf("expected the default mode to refuse yes")
+	}
+	if _, err := def.Get("price").AsInt(); err == nil {
+		t.Errorf("expected the default mode to refuse fractional strings as ints")
+	}
+	if s := def.Get("count").AsStringOr(""); s != "7" {
+		t.Errorf("expected int32 as string, got %q", s)
+	}
+
+	lenient := goutils.NewTreeMap(data).WithConverter(goutils.NewConverter(goutils.CoerceLenient))
+	if n, err := lenient.Get("ratio").AsInt(); err != nil || n != 3 {
+		t.Errorf("expected lenient truncation, got %d (%v)", n, err)
+	}
+	if !lenient.Get("flag").AsBoolOr(false) || !lenient.Get("yes").AsBoolOr(false) {
+		t.Errorf("expected lenient booleans from 1 and yes")
+	}
+	if s := lenient.Get("small").AsStringOr(""); s != "1.5" {
+		t.Errorf("expected float32 as string, got %q", s)
+	}
+	if n := lenient.Get("price").AsIntOr(0); n != 12 {
+		t.Errorf("expected lenient truncation of fractional strings, got %d", n)
+	}
+
+	strict := goutils.NewSyncTreeMap(data).WithConverter(goutils.NewConverter(goutils.CoerceStrict))
+	if _, err := strict.Get("ratio").AsInt(); !errors.Is(err, goutils.ErrLossyConversion) {
+		t.Errorf("expected lossy error, got %v", err)
+	}
+	if _, err := strict.Get("huge").AsFloat(); !errors.Is(err, goutils.ErrLossyConversion) {
+		t.Errorf("expected lossy error for ints above 2^53, got %v", err)
+	}
+	var mismatch *goutils.ErrTypeMismatch
+	if _, err := strict.Get("flag").AsBool(); !errors.As(err, &mismatch) || mismatch.Path != "flag" {
+		t.Errorf("expected strict mode to refuse numeric booleans, got %v", err)
+	}
+	if _, err := strict.Get("yes").AsBool(); err == nil {
+		t.Errorf("expected strict mode to refuse yes")
+	}
+	if n, err := strict.Get("count").AsInt(); err != nil || n != 7 {
+		t.Errorf("expected exact conversions to pass in strict mode, got %d (%v)", n, err)
+	}
+
+	if lenient.Get("ratio").AsIntOr(0) != 3 {
+		t.Errorf("expected other trees to keep their own mode")
+	}
+}
+
+func TestTreeMap_GetAsFollowsMode(t *testing.T) {
+	data := map[string]any{
+		"ratio":  3.7,
+		"padded": " 5 ",
+		"flag":   true,
+		"price":  "3.7",
+		"count":  int32(7),
+	}
+	modes := map[string]goutils.CoercionMode{
+		"default": goutils.CoerceDefault,
+		"lenient": goutils.CoerceLenient,
+		"strict":  goutils.CoerceStrict,
+	}
+
+	for name, mode := range modes {
+		tree := goutils.NewTreeMap(data).WithConverter(goutils.NewConverter(mode))
+		for key := range data {
+			want, wantErr := tree.Get(key).AsInt()
+			got, err := goutils.GetAs[int](tree, key)
+			if (err == nil) != (wantErr == nil) || int64(got) != want {
+				t.Errorf("%s %s: GetAs[int] gave %d (%v), AsInt gave %d (%v)", name, key, got, err, want, wantErr)
+			}
+			wantF, wantErr := tree.Get(key).AsFloat()
+			gotF, err := goutils.GetAs[float64](tree, key)
+			if (err == nil) != (wantErr == nil) || gotF != wantF {
+				t.Errorf("%s %s: GetAs[float64] gave %v (%v), AsFloat gave %v (%v)", name, key, gotF, err, wantF, wantErr)
+			}
+		}
+	}
+}
+
+func TestTreeMap_CustomConversions(t *testing.T) {
+	conv := goutils.NewConverter(goutils.CoerceLenient)
+	goutils.RegisterConversion(conv, func(v any) (coerceLevel, bool, error) {
+		s, ok := v.(string)
+		if !ok {
+			return 0, false, nil
+		}
+		switch s {
+		case "low":
+			return levelLow, true, nil
+		case "high":
+			return levelHigh, true, nil
+		}
+		return 0, true, fmt.Errorf("unknown level %q", s)
+	})
+	goutils.RegisterConversion(conv, func(v any) (float64, bool, error) {
+		if c, ok := v.(coerceCents); ok {
+			return float64(c) / 100, true, nil
+		}
+		return 0, false, nil
+	})
+	goutils.RegisterConversion(conv, func(v any) (string, bool, error) {
+		if c, ok := v.(coerceCents); ok {
+			return fmt.Sprintf("%d.%02d", c/100, c%100), true, nil
+		}
+		return "", false, nil
+	})
+
+	m := goutils.NewTreeMap(map[string]any{
+		"level":  "high",
+		"levels": []any{"low", "high"},
+		"price":  "12.34",
+	}).WithConverter(conv)
+	m.Set("total", coerceCents(1999))
+
+	if lvl, err := goutils.GetAs[coerceLevel](m, "level"); err != nil || lvl != levelHigh {
+		t.Errorf("expected enum conversion, got %v (%v)", lvl, err)
+	}
+	if lvls, err := goutils.GetAs[[]coerceLevel](m, "levels"); err != nil || len(lvls) != 2 || lvls[0] != levelLow {
+		t.Errorf("expected enum conversion inside slices, got %v (%v)", lvls, err)
+	}
+	m.Set("level", "medium")
+	if _, err := goutils.GetAs[coerceLevel](m, "level"); err == nil || !strings.Contains(err.Error(), "unknown level") {
+		t.Errorf("expected conversion error, got %v", err)
+	}
+	if f := m.Get("total").AsFloatOr(0); f != 19.99 {
+		t.Errorf("expected custom decimal as float, got %v", f)
+	}
+	if s := m.Get("total").AsStringOr(""); s != "19.99" {
+		t.Errorf("expected custom decimal as string, got %q", s)
+	}
+	if f := m.Get("price").AsFloatOr(0); f != 12.34 {
+		t.Errorf("expected built-in rules after custom ones, got %v", f)
+	}
+	if _, err := goutils.NewTreeMap(map[string]any{"total": coerceCents(1)}).Get("total").AsFloat(); err == nil {
+		t.Errorf("expected trees without the converter to reject the custom type")
+	}
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Compare Tests -------------------
+
+func TestTreeMap_Compare(t *testing.T) {
+	a := goutils.NewTreeMap(map[string]any{
+		"db":    map[string]any{"host": "localhost", "port": 5432},
+		"tags":  []any{"a", "b"},
+		"debug": true,
+		"ttl":   5,
+	})
+	b := goutils.NewTreeMap(map[string]any{
+		"db":    map[string]any{"host": "db.internal", "port": 5432.0},
+		"tags":  []any{"a"},
+		"debug": "yes",
+		"ttl":   5.0,
+		"new":   1,
+	})
+
+	changes := goutils.Compare(a, b)
+	want := []struct {
+		typ  goutils.ChangeType
+		path string
+	}{
+		{goutils.ChangeModified, "db.host"},
+		{goutils.ChangeTypeChanged, "db.port"},
+		{goutils.ChangeTypeChanged, "debug"},
+		{goutils.ChangeAdded, "new"},
+		{goutils.ChangeRemoved, "tags[1]"},
+		{goutils.ChangeTypeChanged, "ttl"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %v", len(want), changes)
+	}
+	for i, w := range want {
+		if changes[i].Type != w.typ || changes[i].Path != w.path {
+			t.Errorf("change %d: expected %s %s, got %s", i, w.typ, w.path, changes[i])
+		}
+	}
+
+	loose := goutils.Compare(a, b, goutils.CompareOptions{LooseNumbers: true})
+	for _, c := range loose {
+		if c.Path == "db.port" || c.Path == "ttl" {
+			t.Errorf("expected LooseNumbers to treat 5 and 5.0 as equal, got %s", c)
+		}
+	}
+	if len(loose) != 4 {
+		t.Errorf("expected 4 loose changes, got %v", loose)
+	}
+
+	if got := changes[0].String(); got != "modified db.host: localhost (string) -> db.internal (string)" {
+		t.Errorf("unexpected change string %q", got)
+	}
+	if c := goutils.Compare(a, a.Clone()); len(c) != 0 {
+		t.Errorf("expected a clone to have no changes, got %v", c)
+	}
+}
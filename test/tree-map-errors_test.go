@@ -0,0 +1,52 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Error Tests -------------------
+
+func TestTreeMap_Errors(t *testing.T) {
+	m := goutils.NewSyncTreeMap(map[string]any{
+		"users": []any{map[string]any{"name": "ana", "age": "x"}},
+		"count": 3,
+	})
+
+	err := m.Get("users[0].email").Err()
+	var perr *goutils.PathError
+	if !errors.Is(err, goutils.ErrNotFound) || !errors.As(err, &perr) || perr.Path != "users[0].email" {
+		t.Errorf("expected not found at users[0].email, got %v", err)
+	}
+	if m.Get("users[0].email").Exists() || !m.Get("users[0].email").IsEmpty() {
+		t.Errorf("expected a missing path to be empty and not exist")
+	}
+
+	users := m.Get("users")
+	if err := users.Get("[3].name").Err(); !errors.Is(err, goutils.ErrIndexOutOfRange) || err.Error() != "users[3]: index out of range" {
+		t.Errorf("expected index error with the full path, got %v", err)
+	}
+
+	var terr *goutils.ErrTypeMismatch
+	if err := m.Get("count.value").Err(); !errors.As(err, &terr) || terr.Path != "count.value" || terr.Got != "number" {
+		t.Errorf("expected type mismatch at count.value, got %v", err)
+	}
+	if _, err := m.Get("users[0].name").AsInt(); !errors.As(err, &perr) || perr.Path != "users[0].name" {
+		t.Errorf("expected parse error with path, got %v", err)
+	}
+	if _, err := m.Get("users[0]").AsBool(); !errors.As(err, &terr) || terr.Want != "bool" || terr.Got != "object" {
+		t.Errorf("expected bool mismatch, got %v", err)
+	}
+
+	if err := m.Set("count.value", 1).Err(); !errors.As(err, &terr) || terr.Path != "count" {
+		t.Errorf("expected Set through a scalar to report count, got %v", err)
+	}
+	if err := users.Delete("[5]").Err(); !errors.Is(err, goutils.ErrIndexOutOfRange) || !errors.As(err, &perr) || perr.Path != "users[5]" {
+		t.Errorf("expected Delete error with the full path, got %v", err)
+	}
+	if m.Err() != nil {
+		t.Errorf("expected no error on the root")
+	}
+}
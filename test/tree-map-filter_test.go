@@ -0,0 +1,92 @@
+package test
+
+import (
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Filter Expression Tests -------------------
+
+func TestTreeMap_QueryFilter(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"limit": 100,
+		"orders": []any{
+			map[string]any{"id": "o1", "total": 150, "status": "paid"},
+			map[string]any{"id": "o2", "total": 80.5, "status": "paid"},
+			map[string]any{"id": "o3", "total": "250", "status": "pending"},
+			map[string]any{"id": "o4", "total": 300, "status": "paid", "tags": []any{"vip"}},
+		},
+	})
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{`orders[?(@.total > 100 && @.status == "paid")].id`, []string{"o1", "o4"}},
+		{`orders[?(@.total > 100)].id`, []string{"o1", "o3", "o4"}},
+		{`orders[?(@.total <= $.limit)].id`, []string{"o2"}},
+		{`orders[?(@.status != 'paid' || @.total < 100)].id`, []string{"o2", "o3"}},
+		{`orders[?(!(@.status == "paid"))].id`, []string{"o3"}},
+		{`orders[?(@.tags)].id`, []string{"o4"}},
+		{`orders[?(@.tags[0] == "vip")].id`, []string{"o4"}},
+		{`orders[?(@.id =~ /^o[12]$/)].id`, []string{"o1", "o2"}},
+		{`orders[?@.total == 150].id`, []string{"o1"}},
+	}
+
+	for _, c := range cases {
+		res, err := m.Query(c.query)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.query, err)
+			continue
+		}
+		var got []string
+		for _, r := range res {
+			got = append(got, r.AsStringOr(""))
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: expected %v, got %v", c.query, c.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: expected %v, got %v", c.query, c.want, got)
+				break
+			}
+		}
+	}
+
+	m.SetAll(`orders[?(@.status == "pending")].status`, "cancelled")
+	if got := m.Get("orders.2.status").AsStringOr(""); got != "cancelled" {
+		t.Errorf("expected SetAll through a filter, got %q", got)
+	}
+
+	for _, bad := range []string{`orders[?(@.total >)]`, `orders[?(@.total > 1]`, `orders[?(@.id =~ /[/)]`} {
+		if _, err := m.Query(bad); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestTreeMap_QueryFilterPrecision(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"ids": []any{int64(9007199254740993), int64(9007199254740992), uint64(1 << 63)},
+	})
+	res, err := m.Query(`ids[?(@ > 9007199254740992)]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 {
+		t.Errorf("expected integers above 2^53 to compare exactly, got %d matches", len(res))
+	}
+	if res, _ := m.Query(`ids[?(@ == 9007199254740993)]`); len(res) != 1 {
+		t.Errorf("expected exact integer equality, got %d matches", len(res))
+	}
+
+	flags := goutils.NewTreeMap(map[string]any{
+		"items": []any{map[string]any{"on": "yes"}, map[string]any{"on": "no"}},
+	}).WithConverter(goutils.NewConverter(goutils.CoerceLenient))
+	if res, _ := flags.Query(`items[?(@.on == true)]`); len(res) != 1 {
+		t.Errorf("expected filters to use the tree's Converter, got %d matches", len(res))
+	}
+}
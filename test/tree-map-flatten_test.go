@@ -0,0 +1,89 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Flatten Tests -------------------
+
+func TestTreeMap_Flatten(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"db": map[string]any{"host": "localhost", "port": 5432},
+		"servers": []any{
+			map[string]any{"port": 80},
+			map[string]any{"port": 443, "tags": []any{"tls"}},
+		},
+		"empty": map[string]any{},
+	})
+
+	want := goutils.DefaultMap{
+		"db.host":          "localhost",
+		"db.port":          5432,
+		"servers.0.port":   80,
+		"servers.1.port":   443,
+		"servers.1.tags.0": "tls",
+		"empty":            map[string]any{},
+	}
+	if got := m.Flatten(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	safe := goutils.NewSyncTreeMap(map[string]any{
+		"db":      map[string]any{"host": "localhost"},
+		"servers": []any{map[string]any{"port": 80}, map[string]any{"tags": []any{"tls"}}},
+	})
+	got := safe.Flatten(goutils.FlattenOptions{Separator: "/", IndexStyle: goutils.IndexBracket})
+	if got["servers[1]/tags[0]"] != "tls" || got["db/host"] != "localhost" {
+		t.Errorf("unexpected bracket output %v", got)
+	}
+
+	got = m.Flatten(goutils.FlattenOptions{MaxDepth: 2})
+	if got["db.host"] != "localhost" || !reflect.DeepEqual(got["servers.1"], map[string]any{"port": 443, "tags": []any{"tls"}}) {
+		t.Errorf("expected subtrees below depth 2 kept as values, got %v", got)
+	}
+
+	if got := goutils.NewTreeMap("scalar").Flatten(); len(got) != 0 {
+		t.Errorf("expected empty map for scalar, got %v", got)
+	}
+}
+
+func TestTreeMap_Unflatten(t *testing.T) {
+	original := goutils.NewTreeMap(map[string]any{
+		"db": map[string]any{"host": "localhost", "port": 5432},
+		"servers": []any{
+			map[string]any{"port": 80},
+			map[string]any{"port": 443, "tags": []any{"tls"}},
+		},
+		"empty": map[string]any{},
+	})
+
+	for _, opts := range []goutils.FlattenOptions{
+		{},
+		{Separator: "__"},
+		{IndexStyle: goutils.IndexBracket},
+		{MaxDepth: 2},
+	} {
+		back := goutils.Unflatten(original.Flatten(opts), opts)
+		if !back.Exists() {
+			t.Fatalf("unexpected error for %+v", opts)
+		}
+		if changes := goutils.Compare(original, back); len(changes) != 0 {
+			t.Errorf("round trip with %+v changed %v", opts, changes)
+		}
+	}
+
+	m := goutils.Unflatten(map[string]any{"a.0": 1, "a.2": 2, "b.1": "x", "b.0": "y"})
+	if _, ok := m.Get("a").AsAnyOr(nil).(map[string]any); !ok {
+		t.Errorf("expected sparse indexes to stay a map")
+	}
+	if m.Get("b[0]").AsStringOr("") != "y" {
+		t.Errorf("expected b to become a slice")
+	}
+
+	if m := goutils.Unflatten(map[string]any{"a": 1, "a.b": 2}); m.Exists() {
+		t.Errorf("expected conflict error")
+	}
+}
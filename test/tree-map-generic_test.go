@@ -0,0 +1,107 @@
+package test
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Generic Accessor Tests -------------------
+
+type genericItem struct {
+	Name  string   `json:"name"`
+	Qty   uint16   `json:"qty"`
+	Tags  []string `json:"tags"`
+	Price float32
+	Skip  string `json:"-"`
+}
+
+func TestTreeMap_GetAs(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"http": map[string]any{"timeout": "1m30s", "retries": 3.0, "idle": 15},
+		"ip":   "10.0.0.1",
+		"at":   "2024-05-01T10:00:00Z",
+		"items": []any{
+			map[string]any{"name": "a", "qty": 2, "tags": []any{"x"}, "price": 1.5, "Skip": "no"},
+			map[string]any{"name": "b", "qty": "7"},
+		},
+		"big":  300,
+		"frac": 2.5,
+	})
+
+	if d, err := goutils.GetAs[time.Duration](m, "http.timeout"); err != nil || d != 90*time.Second {
+		t.Errorf("expected 1m30s, got %v (%v)", d, err)
+	}
+	if d := goutils.MustGet[time.Duration](m, "http.idle"); d != 15*time.Second {
+		t.Errorf("expected numeric duration in seconds, got %v", d)
+	}
+	if n, err := goutils.GetAs[int8](m, "http.retries"); err != nil || n != 3 {
+		t.Errorf("expected int8 3, got %v (%v)", n, err)
+	}
+	if ip, err := goutils.GetAs[netip.Addr](m, "ip"); err != nil || ip.String() != "10.0.0.1" {
+		t.Errorf("expected TextUnmarshaler conversion, got %v (%v)", ip, err)
+	}
+	if at, err := goutils.GetAs[time.Time](m, "at"); err != nil || at.Year() != 2024 {
+		t.Errorf("expected RFC 3339 time, got %v (%v)", at, err)
+	}
+
+	items, err := goutils.GetAs[[]genericItem](m, "items")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].Qty != 2 || items[0].Tags[0] != "x" || items[0].Price != 1.5 || items[0].Skip != "" || items[1].Qty != 7 {
+		t.Errorf("unexpected struct conversion: %+v", items)
+	}
+
+	if got := goutils.GetAsOr(m, "missing", 42); got != 42 {
+		t.Errorf("expected fallback, got %d", got)
+	}
+}
+
+func TestTreeMap_GetAsErrors(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"big":   300,
+		"neg":   -1,
+		"frac":  2.5,
+		"items": []any{map[string]any{"qty": 70000}},
+	})
+
+	_, err := goutils.GetAs[uint8](m, "big")
+	var cerr *goutils.ConversionError
+	if !errors.As(err, &cerr) || !errors.Is(err, goutils.ErrOutOfRange) || cerr.Path != "big" {
+		t.Errorf("expected out of range ConversionError at big, got %v", err)
+	}
+	if _, err := goutils.GetAs[uint](m, "neg"); !errors.Is(err, goutils.ErrOutOfRange) {
+		t.Errorf("expected negative to uint to be out of range, got %v", err)
+	}
+	strict := m.WithConverter(goutils.NewConverter(goutils.CoerceStrict))
+	if _, err := goutils.GetAs[int](strict, "frac"); !errors.Is(err, goutils.ErrLossyConversion) {
+		t.Errorf("expected fractional to int to fail in strict mode, got %v", err)
+	}
+
+	_, err = goutils.GetAs[[]genericItem](m, "items")
+	if !errors.As(err, &cerr) || cerr.Path != "items[0].qty" {
+		t.Errorf("expected nested error path items[0].qty, got %v", err)
+	}
+
+	var mismatch *goutils.ErrTypeMismatch
+	if _, err := goutils.GetAs[int](m, "items"); !errors.As(err, &mismatch) || mismatch.Path != "items" {
+		t.Errorf("expected a list to int to be an ErrTypeMismatch like AsInt, got %v", err)
+	}
+	if _, err := goutils.GetAs[map[string]int](m, "big"); !errors.As(err, &mismatch) {
+		t.Errorf("expected an unsupported conversion to be an ErrTypeMismatch, got %v", err)
+	}
+	if _, err := goutils.GetAs[uint8](m, "big"); errors.As(err, &mismatch) {
+		t.Errorf("expected out of range not to be a type mismatch")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustGet to panic")
+		}
+	}()
+	goutils.MustGet[bool](m, "items")
+}
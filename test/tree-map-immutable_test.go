@@ -0,0 +1,86 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Immutable Tests -------------------
+
+func TestImmutableTreeMap_Versions(t *testing.T) {
+	data := map[string]any{
+		"db":      map[string]any{"host": "localhost", "port": 5432},
+		"servers": []any{map[string]any{"name": "a"}},
+		"log":     map[string]any{"level": "info"},
+	}
+	v1 := goutils.NewImmutableTreeMap(data)
+	data["db"].(map[string]any)["host"] = "mutated"
+
+	v2 := v1.Set("db.host", "replica")
+	v3 := v2.Delete("log").Set("servers.-", map[string]any{"name": "b"})
+
+	if v1.Get("db.host").AsStringOr("") != "localhost" {
+		t.Errorf("expected v1 to be detached from the input and untouched")
+	}
+	if v2.Get("db.host").AsStringOr("") != "replica" || !v2.IsDefined("log.level") {
+		t.Errorf("unexpected v2 %s", v2.ToJsonString(false))
+	}
+	if v3.IsDefined("log") || v3.Get("servers[1].name").AsStringOr("") != "b" || v2.Get("servers").Len() != 1 {
+		t.Errorf("unexpected v3 %s", v3.ToJsonString(false))
+	}
+
+	if v1.Clone() != v1 {
+		t.Errorf("expected Clone to share the version")
+	}
+	if goutils.Compare(v1.Get("servers"), v2.Get("servers")) != nil {
+		t.Errorf("expected untouched subtrees to stay equal")
+	}
+
+	m, _ := v3.Get("db").AsMap()
+	m["host"] = "changed"
+	if v3.Get("db.host").AsStringOr("") != "replica" {
+		t.Errorf("expected AsMap to return a copy")
+	}
+	if _, ok := v3.Get("db").Set("port", 1).(*goutils.ImmutableTreeMap); !ok {
+		t.Errorf("expected writes on children to stay immutable")
+	}
+}
+
+func TestImmutableTreeMap_ToJsonStringError(t *testing.T) {
+	data := map[string]any{"ratio": math.NaN(), "name": "a"}
+	for _, tree := range []goutils.TreeMapImpl{goutils.NewImmutableTreeMap(data), goutils.NewSyncTreeMap(data)} {
+		if got := tree.ToJsonString(false); got != "{}" {
+			t.Errorf("expected NaN not to marshal, got %s", got)
+		}
+		if tree.Err() != nil || tree.Get("name").AsStringOr("") != "a" {
+			t.Errorf("expected a failed marshal to leave the version usable, got %v", tree.Err())
+		}
+	}
+}
+
+func TestImmutableTreeMap_ChildWrites(t *testing.T) {
+	v1 := goutils.NewImmutableTreeMap(map[string]any{"a": map[string]any{"b": map[string]any{"c": 1}}})
+
+	b := v1.Get("a.b").Set("c", 2)
+	if b.Path() != "a.b" || b.Get("c").AsIntOr(0) != 2 || v1.Get("a.b.c").AsIntOr(0) != 1 {
+		t.Errorf("unexpected child write %s at %q", b.ToJsonString(false), b.Path())
+	}
+	if _, err := v1.Set("a.b.c.d", 1).AsMap(); err == nil {
+		t.Errorf("expected an error through a scalar")
+	}
+	if v := v1.TryDelete("missing.path"); v.Get("a.b.c").AsIntOr(0) != 1 {
+		t.Errorf("expected TryDelete to keep the version")
+	}
+
+	patched := v1.ApplyPatch([]goutils.PatchOperation{{Op: "add", Path: "/a/x", Value: true}})
+	merged := v1.Merge(goutils.NewTreeMap(map[string]any{"a": map[string]any{"y": 1}}))
+	all := v1.SetAll("..c", 9)
+	if !patched.IsDefined("a.x") || !merged.IsDefined("a.y") || all.Get("a.b.c").AsIntOr(0) != 9 {
+		t.Errorf("expected whole-tree writes to return new versions")
+	}
+	if v1.IsDefined("a.x") || v1.IsDefined("a.y") || v1.Get("a.b.c").AsIntOr(0) != 1 {
+		t.Errorf("expected v1 to be untouched, got %s", v1.ToJsonString(false))
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Iteration Tests -------------------
+
+func TestTreeMap_All(t *testing.T) {
+	for _, newTree := range []func(...any) goutils.TreeMapImpl{goutils.NewTreeMap, goutils.NewSyncTreeMap} {
+		m := newTree(map[string]any{
+			"b": map[string]any{"y": 2, "x": 1},
+			"a": []any{"p", map[string]any{"q": true}},
+			"e": map[string]any{},
+		})
+		var paths []string
+		var values []any
+		for path, leaf := range m.All() {
+			paths = append(paths, path)
+			values = append(values, leaf.AsAnyOr(nil))
+		}
+		want := []string{"a[0]", "a[1].q", "b.x", "b.y", "e"}
+		if !slices.Equal(paths, want) {
+			t.Errorf("expected %v, got %v", want, paths)
+		}
+		if values[0] != "p" || values[1] != true {
+			t.Errorf("unexpected leaf values %v", values)
+		}
+
+		for path, leaf := range m.All() {
+			if path != "a[0]" || leaf.Path() != "a[0]" {
+				t.Errorf("expected early break at a[0], got %s", path)
+			}
+			break
+		}
+	}
+
+	var got []string
+	for path := range goutils.NewTreeMap(map[string]any{"b": map[string]any{"y": 2, "x": 1}}).Get("b").All() {
+		got = append(got, path)
+	}
+	if !slices.Equal(got, []string{"x", "y"}) {
+		t.Errorf("expected relative paths, got %v", got)
+	}
+}
+
+func TestTreeMap_KeysEntriesLen(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"b": map[string]any{"y": 2, "x": 1},
+		"a": []any{"p", map[string]any{"q": true}},
+		"e": map[string]any{},
+	})
+
+	if keys := slices.Collect(m.Keys()); !slices.Equal(keys, []string{"a", "b", "e"}) {
+		t.Errorf("expected sorted keys, got %v", keys)
+	}
+	if keys := slices.Collect(m.Get("a").Keys()); !slices.Equal(keys, []string{"0", "1"}) {
+		t.Errorf("expected slice indexes, got %v", keys)
+	}
+	for k, child := range m.Get("b").Entries() {
+		if child.Path() != "b."+k {
+			t.Errorf("expected child path b.%s, got %s", k, child.Path())
+		}
+	}
+	if m.Len() != 3 || m.Get("a").Len() != 2 || m.Get("b.x").Len() != 0 || m.Get("missing").Len() != 0 {
+		t.Errorf("unexpected Len results")
+	}
+}
+
+func TestTreeMap_Walk(t *testing.T) {
+	m := goutils.NewSyncTreeMap(map[string]any{
+		"b": map[string]any{"y": 2, "x": 1},
+		"a": []any{"p", map[string]any{"q": true}},
+		"e": map[string]any{},
+	})
+
+	var visited []string
+	err := m.Walk(func(path string, node goutils.TreeMapImpl) error {
+		visited = append(visited, path)
+		if path == "a" {
+			return goutils.SkipSubtree
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "b.x", "b.y", "e"}; !slices.Equal(visited, want) {
+		t.Errorf("expected %v, got %v", want, visited)
+	}
+
+	visited = nil
+	err = m.Walk(func(path string, node goutils.TreeMapImpl) error {
+		visited = append(visited, path)
+		if path == "b.x" {
+			return goutils.SkipAll
+		}
+		return nil
+	})
+	if err != nil || visited[len(visited)-1] != "b.x" {
+		t.Errorf("expected walk to stop at b.x, got %v (%v)", visited, err)
+	}
+
+	boom := errors.New("boom")
+	if err := m.Walk(func(string, goutils.TreeMapImpl) error { return boom }); !errors.Is(err, boom) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- JSON Decoding Tests -------------------
+
+const jsonNumbersDoc = `{"id": 1234567890123456789, "big": 18446744073709551615, "huge": 123456789012345678901234567890, "price": 9.99, "neg": -42}`
+
+func TestTreeMap_FromJSON(t *testing.T) {
+	m := goutils.NewTreeMapFromJSON(strings.NewReader(jsonNumbersDoc))
+	if !m.Exists() {
+		t.Fatal("unexpected decode error")
+	}
+
+	if id := m.Get("id").AsIntOr(0); id != 1234567890123456789 {
+		t.Errorf("expected exact id, got %d", id)
+	}
+	if s := m.Get("id").AsStringOr(""); s != "1234567890123456789" {
+		t.Errorf("expected exact id string, got %s", s)
+	}
+	if v, ok := m.Get("big").AsAnyOr(nil).(uint64); !ok || v != 18446744073709551615 {
+		t.Errorf("expected uint64, got %T", m.Get("big").AsAnyOr(nil))
+	}
+	if _, err := m.Get("big").AsInt(); err == nil {
+		t.Errorf("expected out of range error for uint64 above MaxInt64")
+	}
+	if s := m.Get("huge").AsStringOr(""); s != "123456789012345678901234567890" {
+		t.Errorf("expected huge integer kept as json.Number, got %s", s)
+	}
+	if f := m.Get("price").AsFloatOr(0); f != 9.99 {
+		t.Errorf("expected float, got %v", f)
+	}
+	if m.Get("neg").AsIntOr(0) != -42 {
+		t.Errorf("expected negative int")
+	}
+	items := goutils.ParseJSON([]byte(`{"items": [{"id": 1234567890123456789}, {"id": 7}]}`))
+	if res, err := items.Query("items[?(@.id > 1000)]"); err != nil || len(res) != 1 {
+		t.Errorf("expected filters to compare decoded ints, got %d (%v)", len(res), err)
+	}
+
+	m = goutils.ParseJSON([]byte(jsonNumbersDoc), goutils.JSONOptions{Numbers: goutils.NumberJSON})
+	if n, ok := m.Get("price").AsAnyOr(nil).(json.Number); !ok || n != "9.99" {
+		t.Errorf("expected json.Number, got %T", m.Get("price").AsAnyOr(nil))
+	}
+	if m.Get("id").AsIntOr(0) != 1234567890123456789 || m.Get("price").AsFloatOr(0) != 9.99 {
+		t.Errorf("expected As* to understand json.Number")
+	}
+	if d := goutils.Compare(m, goutils.ParseJSON([]byte(jsonNumbersDoc)), goutils.CompareOptions{LooseNumbers: true}); len(d) != 0 {
+		t.Errorf("expected json.Number and int64 trees to compare equal, got %v", d)
+	}
+
+	m = goutils.ParseJSON([]byte(`{"id": 1}`), goutils.JSONOptions{Numbers: goutils.NumberFloat})
+	if _, ok := m.Get("id").AsAnyOr(nil).(float64); !ok {
+		t.Errorf("expected float64 in NumberFloat mode")
+	}
+
+	for _, bad := range []string{`{"a":`, `{} {}`, ``} {
+		if goutils.ParseJSON([]byte(bad)).Exists() {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
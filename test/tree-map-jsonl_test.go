@@ -0,0 +1,117 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- JSON Lines Tests -------------------
+
+func TestTreeMap_ReadJSONLines(t *testing.T) {
+	input := "{\"id\": 1}\n\n{\"id\": 9007199254740993}\r\nnot json\n{\"id\": 3}"
+
+	var ids []int64
+	for event, err := range goutils.ReadJSONLines(strings.NewReader(input)) {
+		if err != nil {
+			var lerr *goutils.LineError
+			if !errors.As(err, &lerr) || lerr.Line != 4 {
+				t.Errorf("expected error on line 4, got %v", err)
+			}
+			if event.Exists() {
+				t.Errorf("expected an error tree")
+			}
+			break
+		}
+		ids = append(ids, event.Get("id").AsIntOr(0))
+	}
+	if len(ids) != 2 || ids[1] != 9007199254740993 {
+		t.Errorf("unexpected ids before the bad line: %v", ids)
+	}
+
+	var skipped []error
+	ids = nil
+	opts := goutils.JSONLinesOptions{
+		MaxLineSize:   32,
+		SkipMalformed: true,
+		OnError:       func(err error) { skipped = append(skipped, err) },
+	}
+	long := "{\"id\": 2, \"pad\": \"" + strings.Repeat("x", 64) + "\"}\n"
+	for event, err := range goutils.ReadJSONLines(strings.NewReader("{\"id\": 1}\n"+long+input), opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, event.Get("id").AsIntOr(0))
+	}
+	if len(ids) != 4 || len(skipped) != 2 || !errors.Is(skipped[0], goutils.ErrLineTooLong) {
+		t.Errorf("unexpected ids %v, skipped %v", ids, skipped)
+	}
+
+	count := 0
+	for range goutils.ReadJSONLines(strings.NewReader(input)) {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("expected early break to stop the iterator")
+	}
+}
+
+func TestTreeMap_JSONLinesWriter(t *testing.T) {
+	for _, gz := range []bool{false, true} {
+		var out bytes.Buffer
+		w := goutils.NewJSONLinesWriter(&out, goutils.JSONLinesOptions{Gzip: gz})
+		for i := range 3 {
+			if err := w.Write(goutils.NewTreeMap(map[string]any{"n": i})); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Write(goutils.NewTreeMap().Get("missing[")); err == nil {
+			t.Errorf("expected error tree to be rejected")
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !gz && out.String() != "{\"n\":0}\n{\"n\":1}\n{\"n\":2}\n" {
+			t.Errorf("unexpected output %q", out.String())
+		}
+
+		var got []int64
+		for event, err := range goutils.ReadJSONLines(&out, goutils.JSONLinesOptions{Gzip: gz}) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, event.Get("n").AsIntOr(-1))
+		}
+		if len(got) != 3 || got[2] != 2 {
+			t.Errorf("gzip=%v: unexpected round trip %v", gz, got)
+		}
+	}
+}
+
+func TestTreeMap_ReadJSONLinesTwice(t *testing.T) {
+	var out bytes.Buffer
+	w := goutils.NewJSONLinesWriter(&out, goutils.JSONLinesOptions{Gzip: true})
+	w.Write(goutils.NewTreeMap(map[string]any{"n": 1}))
+	w.Close()
+
+	src := bytes.NewReader(out.Bytes())
+	events := goutils.ReadJSONLines(src, goutils.JSONLinesOptions{Gzip: true})
+	for range 2 {
+		src.Seek(0, io.SeekStart)
+		count := 0
+		for _, err := range events {
+			if err != nil {
+				t.Fatalf("expected the sequence to be reusable after a rewind: %v", err)
+			}
+			count++
+		}
+		if count != 1 {
+			t.Errorf("expected 1 event, got %d", count)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Merge Tests -------------------
+
+func TestTreeMap_MergePatch(t *testing.T) {
+	defaults := goutils.NewTreeMap(map[string]any{
+		"db":    map[string]any{"host": "localhost", "port": 5432, "pool": map[string]any{"max": 10}},
+		"tags":  []any{"a"},
+		"debug": true,
+	})
+	override := goutils.NewTreeMap(map[string]any{
+		"db":    map[string]any{"host": "db.internal", "pool": map[string]any{"max": nil}},
+		"tags":  []any{"b"},
+		"debug": nil,
+		"new":   map[string]any{"x": 1, "gone": nil},
+	})
+
+	if !defaults.Merge(override).Exists() {
+		t.Fatalf("expected merge to succeed")
+	}
+	if got := defaults.Get("db.host").AsStringOr(""); got != "db.internal" {
+		t.Errorf("expected right value to win, got %q", got)
+	}
+	if got := defaults.Get("db.port").AsIntOr(0); got != 5432 {
+		t.Errorf("expected untouched keys to survive, got %d", got)
+	}
+	if defaults.IsDefined("db.pool.max") || defaults.IsDefined("debug") {
+		t.Errorf("expected nulls to delete keys")
+	}
+	added, err := defaults.Get("new").AsMap()
+	if _, found := added["gone"]; err != nil || found || added["x"] != 1 {
+		t.Errorf("expected nested nulls in added maps to be dropped, got %v", added)
+	}
+	if got := defaults.Get("tags").AsStrSlice(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("expected arrays to be replaced, got %v", got)
+	}
+}
+
+func TestTreeMap_MergeStrategies(t *testing.T) {
+	base := func() goutils.TreeMapImpl {
+		return goutils.NewTreeMap(map[string]any{
+			"name":  "left",
+			"tags":  []any{"a"},
+			"users": []any{map[string]any{"id": 1, "role": "admin"}, map[string]any{"id": 2, "role": "dev"}},
+			"keep":  "me",
+		})
+	}
+	other := goutils.NewTreeMap(map[string]any{
+		"name":  "right",
+		"tags":  []any{"b"},
+		"users": []any{map[string]any{"id": 2.0, "role": "ops"}, map[string]any{"id": 3, "role": "qa"}},
+		"keep":  nil,
+	})
+
+	m := base().Merge(other, goutils.MergeOptions{Arrays: goutils.ArrayAppend, Conflicts: goutils.ConflictLeftWins, Nulls: goutils.NullSkip})
+	if got := m.Get("name").AsStringOr(""); got != "left" {
+		t.Errorf("expected left to win, got %q", got)
+	}
+	if got := m.Get("tags").AsStrSlice(); len(got) != 2 || got[1] != "b" {
+		t.Errorf("expected appended tags, got %v", got)
+	}
+	if got := m.Get("keep").AsStringOr(""); got != "me" {
+		t.Errorf("expected NullSkip to keep left value, got %q", got)
+	}
+
+	m = base().Merge(other, goutils.MergeOptions{Arrays: goutils.ArrayMergeByKey, Nulls: goutils.NullSet})
+	if n := len(m.Get("users").AsAnySlice()); n != 3 {
+		t.Fatalf("expected 3 users after merge by id, got %d", n)
+	}
+	if got := m.Get("users.1.role").AsStringOr(""); got != "ops" {
+		t.Errorf("expected user 2 to be merged by id, got %q", got)
+	}
+	if !m.Get("keep").IsEmpty() || !m.Get("keep").Exists() {
+		t.Errorf("expected NullSet to store null")
+	}
+
+	strict := base()
+	before := strict.ToJsonString(false)
+	if strict.Merge(other, goutils.MergeOptions{Conflicts: goutils.ConflictError}).Exists() {
+		t.Errorf("expected conflicting merge to fail")
+	}
+	if strict.ToJsonString(false) != before {
+		t.Errorf("expected failed merge to leave the tree untouched")
+	}
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- JSON Patch Tests -------------------
+
+func TestTreeMap_ApplyPatch(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"name":  "svc",
+		"tags":  []any{"a", "c"},
+		"owner": map[string]any{"team": "core"},
+	})
+
+	ops, err := goutils.ParsePatch([]byte(`[
+		{"op": "test", "path": "/name", "value": "svc"},
+		{"op": "add", "path": "/tags/1", "value": "b"},
+		{"op": "add", "path": "/tags/-", "value": "d"},
+		{"op": "replace", "path": "/owner/team", "value": "platform"},
+		{"op": "copy", "from": "/owner", "path": "/backup"},
+		{"op": "move", "from": "/name", "path": "/service"},
+		{"op": "remove", "path": "/tags/0"}
+	]`))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	if res := m.ApplyPatch(ops); !res.Exists() {
+		t.Fatalf("expected patch to apply")
+	}
+	if got := m.Get("tags").AsStrSlice(); len(got) != 3 || got[0] != "b" || got[2] != "d" {
+		t.Errorf("expected tags [b c d], got %v", got)
+	}
+	if m.IsDefined("name") || m.Get("service").AsStringOr("") != "svc" {
+		t.Errorf("expected name to be moved to service")
+	}
+	if got := m.Get("backup.team").AsStringOr(""); got != "platform" {
+		t.Errorf("expected copied owner, got %q", got)
+	}
+}
+
+func TestTreeMap_ApplyPatchAtomic(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{"count": 1, "items": []any{1}})
+	before := m.ToJsonString(false)
+
+	res := m.ApplyPatch([]goutils.PatchOperation{
+		{Op: "replace", Path: "/count", Value: 2},
+		{Op: "add", Path: "/items/5", Value: 9},
+	})
+	if res.Exists() {
+		t.Errorf("expected out of range add to fail")
+	}
+	if after := m.ToJsonString(false); after != before {
+		t.Errorf("expected failed patch to roll back, got %s", after)
+	}
+
+	if !m.ApplyPatch([]goutils.PatchOperation{{Op: "test", Path: "/count", Value: 1.0}}).Exists() {
+		t.Errorf("expected test op to treat 1 and 1.0 as equal")
+	}
+	if m.ApplyPatch([]goutils.PatchOperation{{Op: "remove", Path: "/missing"}}).Exists() {
+		t.Errorf("expected remove of missing path to fail")
+	}
+}
+
+func TestTreeMap_Diff(t *testing.T) {
+	a := goutils.NewTreeMap(map[string]any{
+		"name":  "svc",
+		"port":  8080,
+		"tags":  []any{"a", "b", "c"},
+		"debug": true,
+	})
+	b := goutils.NewTreeMap(map[string]any{
+		"name": "svc",
+		"port": 8080.0,
+		"tags": []any{"a", "x"},
+		"a/b":  1,
+	})
+
+	ops := goutils.Diff(a, b)
+	encoded, _ := json.Marshal(ops)
+	want := `[{"op":"add","path":"/a~1b","value":1},{"op":"remove","path":"/debug"},{"op":"replace","path":"/tags/1","value":"x"},{"op":"remove","path":"/tags/2"}]`
+	if string(encoded) != want {
+		t.Errorf("unexpected diff:\n got %s\nwant %s", encoded, want)
+	}
+
+	if a.ApplyPatch(ops); goutils.Diff(a, b) != nil {
+		t.Errorf("expected applying the diff to make both trees equal")
+	}
+}
+
+func TestTreeMap_DiffSlices(t *testing.T) {
+	cases := []struct {
+		from, to []any
+		want     string
+	}{
+		{[]any{1, 2, 3}, []any{0, 1, 2, 3}, `[{"op":"add","path":"/list/0","value":0}]`},
+		{[]any{1, 2, 3}, []any{1, 3}, `[{"op":"remove","path":"/list/1"}]`},
+		{[]any{1, 2, 3}, []any{1, 2, 9, 3}, `[{"op":"add","path":"/list/2","value":9}]`},
+		{
+			[]any{map[string]any{"id": 1, "n": "a"}, 2},
+			[]any{map[string]any{"id": 1, "n": "b"}, 2},
+			`[{"op":"replace","path":"/list/0/n","value":"b"}]`,
+		},
+	}
+	for _, c := range cases {
+		a := goutils.NewTreeMap(map[string]any{"list": c.from})
+		b := goutils.NewTreeMap(map[string]any{"list": c.to})
+		ops := goutils.Diff(a, b)
+		if encoded, _ := json.Marshal(ops); string(encoded) != c.want {
+			t.Errorf("%v -> %v:\n got %s\nwant %s", c.from, c.to, encoded, c.want)
+		}
+		if a.ApplyPatch(ops); goutils.Diff(a, b) != nil {
+			t.Errorf("%v -> %v: expected the diff to apply cleanly", c.from, c.to)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Path Syntax Tests -------------------
+
+func TestTreeMap_PathSyntax(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"metadata": map[string]any{
+			"labels": map[string]any{
+				"app.kubernetes.io/name": "api",
+			},
+		},
+		"user.email": "alice@example.com",
+		"items":      []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+	})
+
+	if got := m.Get(`metadata.labels["app.kubernetes.io/name"]`).AsStringOr(""); got != "api" {
+		t.Errorf("expected bracket key lookup to return 'api', got %q", got)
+	}
+	if got := m.Get(`metadata.labels['app.kubernetes.io/name']`).AsStringOr(""); got != "api" {
+		t.Errorf("expected single quoted key lookup to return 'api', got %q", got)
+	}
+	if got := m.Get(`user\.email`).AsStringOr(""); got != "alice@example.com" {
+		t.Errorf("expected escaped dot lookup, got %q", got)
+	}
+	if got := m.Get(`"user.email"`).AsStringOr(""); got != "alice@example.com" {
+		t.Errorf("expected quoted dotted segment lookup, got %q", got)
+	}
+	if got := m.Get("items[1].id").AsIntOr(0); got != 2 {
+		t.Errorf("expected items[1].id = 2, got %d", got)
+	}
+	if !m.IsDefined(`["user.email"]`) {
+		t.Errorf("expected leading bracket key to be defined")
+	}
+
+	m.Set(`metadata.labels["app.kubernetes.io/version"]`, "v1")
+	if got := m.Get(`metadata.labels.app\.kubernetes\.io/version`).AsStringOr(""); got != "v1" {
+		t.Errorf("expected Set with bracket key, got %q", got)
+	}
+
+	m.Delete(`metadata.labels["app.kubernetes.io/name"]`)
+	if m.IsDefined(`metadata.labels["app.kubernetes.io/name"]`) {
+		t.Errorf("expected bracket key to be deleted")
+	}
+
+	if got := m.Get("missing").Or(`["user.email"]`).AsStringOr(""); got != "alice@example.com" {
+		t.Errorf("expected Or with bracket key, got %q", got)
+	}
+
+	for _, bad := range []string{`a["b`, `a[b]`, `a[0`, `a\`, `a["b"]c`} {
+		if m.Get(bad).Exists() {
+			t.Errorf("expected invalid path %q to fail", bad)
+		}
+	}
+}
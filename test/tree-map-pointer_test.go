@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- JSON Pointer Tests -------------------
+
+func TestTreeMap_JSONPointer(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"users": []any{map[string]any{"name": "alice"}},
+		"a/b":   map[string]any{"m~n": 7},
+	})
+
+	if got := m.GetPointer("/users/0/name").AsStringOr(""); got != "alice" {
+		t.Errorf("expected /users/0/name = alice, got %q", got)
+	}
+	if got := m.GetPointer("/a~1b/m~0n").AsIntOr(0); got != 7 {
+		t.Errorf("expected escaped pointer lookup, got %d", got)
+	}
+	if _, err := m.GetPointer("").AsMap(); err != nil {
+		t.Errorf("expected empty pointer to be the whole document: %v", err)
+	}
+
+	m.SetPointer("/users/-", map[string]any{"name": "bob"})
+	if got := m.Get("users.1.name").AsStringOr(""); got != "bob" {
+		t.Errorf("expected SetPointer append, got %q", got)
+	}
+
+	if removed := m.DeletePointer("/users/0"); removed.Get("name").AsStringOr("") != "alice" {
+		t.Errorf("expected DeletePointer to return removed element")
+	}
+
+	node := m.Get(`["a/b"]["m~n"]`)
+	if got := node.Pointer(); got != "/a~1b/m~0n" {
+		t.Errorf("expected pointer /a~1b/m~0n, got %q", got)
+	}
+	if got := m.Get("users.0.name").Pointer(); got != "/users/0/name" {
+		t.Errorf("expected pointer /users/0/name, got %q", got)
+	}
+
+	for _, bad := range []string{"users", "/a~2b", "/a~", "/users/+0", "/users/00", "/users/-0", "/users/ 0"} {
+		if m.GetPointer(bad).Exists() {
+			t.Errorf("expected invalid pointer %q to fail", bad)
+		}
+	}
+	for _, bad := range []string{"users[+0]", "users[00]", "users[01]", "users.+0"} {
+		if m.Get(bad).Exists() || m.Set(bad, "x").Err() == nil {
+			t.Errorf("expected path %q to reject its index", bad)
+		}
+	}
+	if m.SetPointer("/users/01", "x").Err() == nil {
+		t.Errorf("expected SetPointer with a leading zero index to fail")
+	}
+	ops := []goutils.PatchOperation{{Op: "add", Path: "/users/+0", Value: "x"}}
+	if m.ApplyPatch(ops).Err() == nil {
+		t.Errorf("expected patch add with a signed index to fail")
+	}
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Query Tests -------------------
+
+func TestTreeMap_QueryWildcard(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"users": []any{
+			map[string]any{"id": 1, "email": "a@x.io", "active": true},
+			map[string]any{"id": 2, "email": "b@x.io"},
+			map[string]any{"id": 3, "email": "c@x.io", "active": true},
+		},
+		"meta": map[string]any{"id": "m-1"},
+	})
+
+	res, err := m.Query("users.*.email")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("expected 3 emails, got %d", len(res))
+	}
+	if res[1].Path() != "users[1].email" || res[1].AsStringOr("") != "b@x.io" {
+		t.Errorf("unexpected match %s = %v", res[1].Path(), res[1].AsAnyOr(nil))
+	}
+
+	res, _ = m.Query("users[*].id")
+	if len(res) != 3 {
+		t.Errorf("expected bracket wildcard to match 3 ids, got %d", len(res))
+	}
+}
+
+func TestTreeMap_QueryRecursiveAndSlices(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"users": []any{
+			map[string]any{"id": 1, "email": "a@x.io", "active": true},
+			map[string]any{"id": 2, "email": "b@x.io"},
+			map[string]any{"id": 3, "email": "c@x.io", "active": true},
+		},
+		"meta": map[string]any{"id": "m-1"},
+	})
+
+	res, _ := m.Query("..id")
+	if len(res) != 4 {
+		t.Fatalf("expected 4 ids across the tree, got %d", len(res))
+	}
+	if res[0].Path() != "meta.id" {
+		t.Errorf("expected map keys to be visited in sorted order, got %s", res[0].Path())
+	}
+
+	res, _ = m.Query("users[1:3].id")
+	if len(res) != 2 || res[0].AsIntOr(0) != 2 || res[1].AsIntOr(0) != 3 {
+		t.Errorf("expected ids [2 3] from slice range, got %v", res)
+	}
+
+	res, _ = m.Query("users[-1:].id")
+	if len(res) != 1 || res[0].AsIntOr(0) != 3 {
+		t.Errorf("expected negative start slice to return last id")
+	}
+
+	res, _ = m.Query("users[::2].id")
+	if len(res) != 2 || res[1].Path() != "users[2].id" {
+		t.Errorf("expected stepped slice to match users 0 and 2")
+	}
+
+	if _, err := m.Query("users[1:2:0]"); err == nil {
+		t.Errorf("expected zero step to be rejected")
+	}
+	if m.Get("users.*.id").Exists() {
+		t.Errorf("expected Get to reject wildcard paths")
+	}
+}
+
+func TestTreeMap_SetAllDeleteAll(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"users": []any{
+			map[string]any{"id": 1, "email": "a@x.io", "active": true},
+			map[string]any{"id": 2, "email": "b@x.io"},
+			map[string]any{"id": 3, "email": "c@x.io", "active": true},
+		},
+		"meta": map[string]any{"id": "m-1"},
+	})
+
+	m.SetAll("users.*.active", false)
+	for _, u := range m.Get("users").AsAnySlice() {
+		if u.(map[string]any)["active"] != false {
+			t.Errorf("expected every user to be inactive, got %v", u)
+		}
+	}
+
+	err := m.SetAll("*.5000", "x").Err()
+	if !errors.Is(err, goutils.ErrIndexOutOfRange) {
+		t.Errorf("expected SetAll to fail on users.5000, got %v", err)
+	}
+	if m.IsDefined(`meta["5000"]`) {
+		t.Errorf("expected a failed SetAll to leave every target untouched")
+	}
+
+	m.DeleteAll("users[0:2]")
+	if ids := m.Get("users").AsAnySlice(); len(ids) != 1 {
+		t.Fatalf("expected 1 remaining user, got %d", len(ids))
+	}
+	if got := m.Get("users.0.id").AsIntOr(0); got != 3 {
+		t.Errorf("expected remaining user to be id 3, got %d", got)
+	}
+
+	m.DeleteAll("..email")
+	if m.IsDefined("users.0.email") {
+		t.Errorf("expected recursive DeleteAll to remove emails")
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- JSON Schema Tests -------------------
+
+var userSchema = goutils.NewTreeMap(map[string]any{
+	"$defs": map[string]any{
+		"role": map[string]any{"enum": []any{"admin", "editor", "viewer"}},
+	},
+	"type":     "object",
+	"required": []any{"name", "email", "age"},
+	"properties": map[string]any{
+		"name":  map[string]any{"type": "string", "minLength": 2, "maxLength": 20},
+		"email": map[string]any{"type": "string", "format": "email"},
+		"age":   map[string]any{"type": "integer", "minimum": 18, "exclusiveMaximum": 130},
+		"id":    map[string]any{"type": "string", "format": "uuid"},
+		"roles": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/role"}, "uniqueItems": true},
+		"code":  map[string]any{"type": "string", "pattern": "^[A-Z]{3}$"},
+		"contact": map[string]any{
+			"oneOf": []any{
+				map[string]any{"type": "object", "required": []any{"phone"}},
+				map[string]any{"type": "object", "required": []any{"fax"}},
+			},
+		},
+		"score": map[string]any{"anyOf": []any{map[string]any{"type": "null"}, map[string]any{"type": "number"}}},
+	},
+	"additionalProperties": false,
+})
+
+func TestTreeMap_ValidateOK(t *testing.T) {
+	doc := goutils.NewTreeMap(map[string]any{
+		"name":    "Alice",
+		"email":   "alice@example.com",
+		"age":     28.0,
+		"id":      "4f9b8b8e-3c2d-4a55-9a6e-0b6c1f1e2d3a",
+		"roles":   []any{"admin", "viewer"},
+		"code":    "ARG",
+		"contact": map[string]any{"phone": "123"},
+		"score":   nil,
+	})
+	if err := doc.Validate(userSchema); err != nil {
+		t.Errorf("expected document to be valid, got %v", err)
+	}
+}
+
+func TestTreeMap_ValidateViolations(t *testing.T) {
+	doc := goutils.NewTreeMap(map[string]any{
+		"name":    "A",
+		"age":     17.5,
+		"id":      "nope",
+		"roles":   []any{"admin", "root", "admin"},
+		"code":    "ar",
+		"contact": map[string]any{"phone": "1", "fax": "2"},
+		"score":   "high",
+		"extra":   true,
+	})
+
+	err := doc.Validate(userSchema)
+	var verr *goutils.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	got := map[string]string{}
+	for _, v := range verr.Violations {
+		got[v.Pointer+" "+v.Keyword] = v.Message
+	}
+	for _, want := range []string{
+		"/email required",
+		"/name minLength",
+		"/age type",
+		"/id format",
+		"/roles uniqueItems",
+		"/roles/1 enum",
+		"/code pattern",
+		"/contact oneOf",
+		"/score anyOf",
+		"/extra additionalProperties",
+	} {
+		if _, ok := got[want]; !ok {
+			t.Errorf("expected violation %q, got %v", want, verr.Violations)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Semantic Accessor Tests -------------------
+
+func TestTreeMap_AsTime(t *testing.T) {
+	m := goutils.NewSyncTreeMap(map[string]any{
+		"rfc":    "2024-05-01T10:00:00Z",
+		"unix":   1714557600,
+		"millis": 1714557600000,
+		"str":    "1714557600",
+		"date":   "01/05/2024",
+		"bad":    "yesterday",
+		"list":   []any{"2024-05-01T10:00:00Z", 1714557600, "nope"},
+	})
+	want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, path := range []string{"rfc", "unix", "millis", "str"} {
+		if got, err := m.Get(path).AsTime(); err != nil || !got.Equal(want) {
+			t.Errorf("%s: expected %v, got %v (%v)", path, want, got, err)
+		}
+	}
+	if got := m.Get("date").AsTimeOr(time.Time{}, "02/01/2006"); got.Format(time.DateOnly) != "2024-05-01" {
+		t.Errorf("expected custom layout, got %v", got)
+	}
+	if _, err := m.Get("bad").AsTime(); err == nil {
+		t.Errorf("expected error for an unparseable time")
+	}
+	if got := m.Get("bad").AsTimeOr(want); !got.Equal(want) {
+		t.Errorf("expected fallback")
+	}
+	if list := m.Get("list").AsTimeSlice(); len(list) != 3 || !list[1].Equal(want) || !list[2].IsZero() {
+		t.Errorf("unexpected time slice %v", list)
+	}
+}
+
+func TestTreeMap_SemanticAccessors(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"timeout": "1m30s",
+		"idle":    2.5,
+		"api":     "https://api.example.com/v1?x=1",
+		"rel":     "/v1",
+		"ip":      "10.0.0.1",
+		"ips":     []any{"::1", "bad"},
+		"net":     "10.0.0.0/8",
+		"id":      "9b2d6a3e-3f4c-4e7a-9d3b-2f1e6c5b4a39",
+		"size":    "10MiB",
+		"sizes":   []any{"1.5 GB", "64k", 512, "2xb"},
+		"obj":     map[string]any{},
+	})
+
+	if d := m.Get("timeout").AsDurationOr(0); d != 90*time.Second {
+		t.Errorf("expected 1m30s, got %v", d)
+	}
+	if d := m.Get("idle").AsDurationOr(0); d != 2500*time.Millisecond {
+		t.Errorf("expected numeric seconds, got %v", d)
+	}
+	m.Set("ttl", 5*time.Second)
+	if d, err := m.Get("ttl").AsDuration(); err != nil || d != 5*time.Second {
+		t.Errorf("expected a stored time.Duration unchanged, got %v (%v)", d, err)
+	}
+	if d, err := goutils.GetAs[time.Duration](m, "ttl"); err != nil || d != 5*time.Second {
+		t.Errorf("expected GetAs to agree with AsDuration, got %v (%v)", d, err)
+	}
+	if u, err := m.Get("api").AsURL(); err != nil || u.Host != "api.example.com" || u.Query().Get("x") != "1" {
+		t.Errorf("unexpected url %v (%v)", u, err)
+	}
+	fallback := &url.URL{Scheme: "http", Host: "localhost"}
+	if u := m.Get("rel").AsURLOr(fallback); u != fallback {
+		t.Errorf("expected relative url to be rejected, got %v", u)
+	}
+	if ip := m.Get("ip").AsIPOr(netip.Addr{}); ip != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("unexpected ip %v", ip)
+	}
+	if ips := m.Get("ips").AsIPSlice(); len(ips) != 2 || !ips[0].IsLoopback() || ips[1].IsValid() {
+		t.Errorf("unexpected ip slice %v", ips)
+	}
+	if p, err := m.Get("net").AsCIDR(); err != nil || !p.Contains(netip.MustParseAddr("10.1.2.3")) {
+		t.Errorf("unexpected cidr %v (%v)", p, err)
+	}
+	if id := m.Get("id").AsUUIDOr(uuid.Nil); id.String() != "9b2d6a3e-3f4c-4e7a-9d3b-2f1e6c5b4a39" {
+		t.Errorf("unexpected uuid %v", id)
+	}
+	if n := m.Get("size").AsByteSizeOr(0); n != 10<<20 {
+		t.Errorf("expected 10MiB, got %d", n)
+	}
+	if sizes := m.Get("sizes").AsByteSizeSlice(); len(sizes) != 4 || sizes[0] != 1_500_000_000 || sizes[1] != 64_000 || sizes[2] != 512 || sizes[3] != 0 {
+		t.Errorf("unexpected sizes %v", sizes)
+	}
+
+	var mismatch *goutils.ErrTypeMismatch
+	if _, err := m.Get("obj").AsUUID(); !errors.As(err, &mismatch) || mismatch.Path != "obj" || mismatch.Want != "uuid" {
+		t.Errorf("expected type mismatch, got %v", err)
+	}
+	var perr *goutils.PathError
+	if _, err := m.Get("ip").AsCIDR(); !errors.As(err, &perr) || perr.Path != "ip" {
+		t.Errorf("expected parse error with path, got %v", err)
+	}
+}
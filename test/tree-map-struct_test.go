@@ -0,0 +1,149 @@
+package test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	goutils "github.com/nitsugaro/go-utils"
+)
+
+// ------------------- Struct Normalization Tests -------------------
+
+type StructAudit struct {
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type structLevel int
+
+func (l structLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Repeat("*", int(l)))
+}
+
+type structAddress struct {
+	City string `json:"city"`
+}
+
+type structUser struct {
+	StructAudit
+	Name     string          `json:"name"`
+	Email    string          `json:"email,omitempty"`
+	Password string          `json:"-"`
+	Address  *structAddress  `json:"address"`
+	Previous *structAddress  `json:"previous"`
+	Level    structLevel     `json:"level"`
+	Tags     []string        `json:"tags,omitempty"`
+	Meta     map[string]any  `json:"meta"`
+	Friends  []structAddress `json:"friends"`
+	Raw      json.RawMessage `json:"raw"`
+	NoTag    bool
+	Zero     structAddress     `json:"zero,omitzero"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	hidden   string
+}
+
+func TestTreeMap_FromStruct(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	user := &structUser{
+		StructAudit: StructAudit{CreatedBy: "admin", CreatedAt: at},
+		Name:        "ana",
+		Password:    "secret",
+		Address:     &structAddress{City: "Lima"},
+		Level:       3,
+		Meta:        map[string]any{"team": structAddress{City: "Quito"}},
+		Friends:     []structAddress{{City: "Cusco"}},
+		Raw:         json.RawMessage(`{"k":[1,2]}`),
+		NoTag:       true,
+		hidden:      "x",
+	}
+
+	m := goutils.FromStruct(user)
+	checks := map[string]string{
+		"name":            "ana",
+		"address.city":    "Lima",
+		"created_by":      "admin",
+		"created_at":      "2024-05-01T10:00:00Z",
+		"level":           "***",
+		"meta.team.city":  "Quito",
+		"friends[0].city": "Cusco",
+		"NoTag":           "true",
+	}
+	for path, want := range checks {
+		if got := m.Get(path).AsStringOr(""); got != want {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+	if m.Get("raw.k[1]").AsIntOr(0) != 2 {
+		t.Errorf("expected json.RawMessage to be decoded")
+	}
+	for _, path := range []string{"email", "Password", "tags", "zero", "extra", "hidden", "StructAudit"} {
+		if m.IsDefined(path) {
+			t.Errorf("expected %s to be omitted", path)
+		}
+	}
+	if !m.Get("previous").Exists() || m.Get("previous").AsAnyOr("x") != nil {
+		t.Errorf("expected nil pointer to become null")
+	}
+
+	if goutils.FromStruct("nope").Exists() {
+		t.Errorf("expected error for a non-struct value")
+	}
+}
+
+func TestTreeMap_SetStruct(t *testing.T) {
+	m := goutils.NewTreeMap()
+	m.Set("user", structAddress{City: "Lima"})
+	if m.Get("user.city").AsStringOr("") != "Lima" {
+		t.Errorf("expected Set to normalize structs")
+	}
+
+	clone := goutils.NewTreeMap(map[string]any{"a": nil, "l": []any{nil, 1}}).Clone()
+	if clone.ToJsonString(false) != `{"a":null,"l":[null,1]}` {
+		t.Errorf("expected nulls to survive Clone, got %s", clone.ToJsonString(false))
+	}
+
+	m.Set("id", big.NewInt(1234567890123456789))
+	m.Set("raw", json.RawMessage(`{"n":9007199254740993,"f":1.5}`))
+	if got, _ := m.Get("id").AsInt(); got != 1234567890123456789 {
+		t.Errorf("expected a Marshaler number to keep its precision, got %v", m.Get("id").AsAnyOr(nil))
+	}
+	if got, _ := m.Get("raw.n").AsInt(); got != 9007199254740993 {
+		t.Errorf("expected raw JSON ids to keep their precision, got %v", m.Get("raw.n").AsAnyOr(nil))
+	}
+	if got := m.Get("raw.f").AsAnyOr(nil); got != 1.5 {
+		t.Errorf("expected fractions to stay float64, got %v", got)
+	}
+}
+
+func TestTreeMap_SetTextValues(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	api, _ := url.Parse("https://api.example.com/v1?x=1")
+	m := goutils.NewTreeMap()
+	m.Set("ip", netip.MustParseAddr("10.0.0.1"))
+	m.Set("api", api)
+	m.Set("home", *api)
+	m.Set("at", at)
+	m.Set("server", struct {
+		Bind netip.Addr `json:"bind"`
+	}{netip.MustParseAddr("::1")})
+
+	if ip, err := m.Get("ip").AsIP(); err != nil || ip.String() != "10.0.0.1" {
+		t.Errorf("expected netip.Addr stored as text, got %v (%v)", m.Get("ip").AsAnyOr(nil), err)
+	}
+	for _, path := range []string{"api", "home"} {
+		if u, err := m.Get(path).AsURL(); err != nil || u.String() != api.String() {
+			t.Errorf("expected %s stored as a URL string, got %v (%v)", path, m.Get(path).AsAnyOr(nil), err)
+		}
+	}
+	if got, err := m.Get("at").AsTime(); err != nil || !got.Equal(at) {
+		t.Errorf("expected time.Time to round trip, got %v (%v)", got, err)
+	}
+	if got := m.Get("server.bind").AsStringOr(""); got != "::1" {
+		t.Errorf("expected fields to use their text form, got %q", got)
+	}
+}
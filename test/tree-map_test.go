@@ -1,6 +1,7 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -150,3 +151,93 @@ func TestSafeTreeMap_CloneIsolation(t *testing.T) {
 		t.Errorf("clone modifications must not affect original SafeTreeMap")
 	}
 }
+
+// ------------------- Array Set / Delete Tests -------------------
+
+func TestTreeMap_ArraySetDelete(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"items": []any{
+			map[string]any{"name": "a"},
+			map[string]any{"name": "b"},
+			map[string]any{"name": "c"},
+		},
+	})
+
+	m.Set("items.2.name", "z")
+	if got := m.Get("items.2.name").AsStringOr(""); got != "z" {
+		t.Errorf("expected items.2.name = z, got %q", got)
+	}
+
+	m.Set("items.-", map[string]any{"name": "d"})
+	if got := m.Get("items.3.name").AsStringOr(""); got != "d" {
+		t.Errorf("expected appended item at index 3, got %q", got)
+	}
+
+	removed := m.Delete("items.1")
+	if got := removed.Get("name").AsStringOr(""); got != "b" {
+		t.Errorf("expected Delete to return removed element, got %q", got)
+	}
+	if names := len(m.Get("items").AsAnySlice()); names != 3 {
+		t.Errorf("expected 3 items after splice, got %d", names)
+	}
+	if got := m.Get("items.1.name").AsStringOr(""); got != "z" {
+		t.Errorf("expected items to shift after splice, got %q", got)
+	}
+
+	m.Set("matrix.0.1", "x")
+	if got := m.Get("matrix[0][1]").AsStringOr(""); got != "x" {
+		t.Errorf("expected nested arrays to be auto-created, got %q", got)
+	}
+	if !m.Get("matrix.0.0").IsEmpty() {
+		t.Errorf("expected padded slots to be nil")
+	}
+
+	m.Set(`codes["404"]`, "not found")
+	if _, err := m.Get("codes").AsMap(); err != nil {
+		t.Errorf("expected quoted numeric key to create a map: %v", err)
+	}
+
+	if m.Set("items.0.name.deep", 1).Exists() {
+		t.Errorf("expected Set through a scalar to fail")
+	}
+	if m.Delete("items.9").Exists() {
+		t.Errorf("expected Delete out of range to fail")
+	}
+	if err := m.Set("huge[2000000000]", 1).Err(); !errors.Is(err, goutils.ErrIndexOutOfRange) {
+		t.Errorf("expected padding past MaxSlicePadding to fail, got %v", err)
+	}
+}
+
+func TestTreeMap_SliceViewWrites(t *testing.T) {
+	m := goutils.NewTreeMap(map[string]any{
+		"items": []any{1},
+		"a":     map[string]any{"list": []any{"x", "y"}, "empty": nil},
+	})
+
+	if err := m.Get("items").Set("-", 2).Err(); err != nil {
+		t.Fatalf("append through a view: %v", err)
+	}
+	if got := m.Get("items").AsAnySlice(); len(got) != 2 {
+		t.Errorf("expected the append to reach the root, got %v", got)
+	}
+
+	ops := []goutils.PatchOperation{{Op: "add", Path: "/-", Value: "z"}}
+	if err := m.Get("a.list").ApplyPatch(ops).Err(); err != nil {
+		t.Fatalf("patch through a view: %v", err)
+	}
+	m.Get("a.list").Delete("0")
+	if got := m.Get("a.list").AsStrSlice(); len(got) != 2 || got[0] != "y" || got[1] != "z" {
+		t.Errorf("expected [y z] at the root, got %v", got)
+	}
+
+	m.Get("a.empty").SetPointer("", map[string]any{"k": "v"})
+	if got := m.Get("a.empty.k").AsStringOr(""); got != "v" {
+		t.Errorf("expected a null view to become a map at the root, got %q", got)
+	}
+
+	clone := m.Get("items").Clone()
+	clone.Set("-", 3)
+	if got := m.Get("items").AsAnySlice(); len(got) != 2 {
+		t.Errorf("expected clones to stay detached, got %v", got)
+	}
+}
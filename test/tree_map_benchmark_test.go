@@ -1,8 +1,10 @@
 package test
 
 import (
+	"fmt"
 	"math/rand"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -94,3 +96,125 @@ func BenchmarkRawMapOverhead(b *testing.B) {
 		delete(raw, strconv.Itoa(rand.Intn(1000)))
 	}
 }
+
+func benchUsers() map[string]any {
+	users := make(map[string]any, 1000)
+	for i := 0; i < 1000; i++ {
+		users[strconv.Itoa(i)] = map[string]any{
+			"id":     i,
+			"name":   "User_" + strconv.Itoa(i),
+			"active": i%2 == 0,
+		}
+	}
+	return map[string]any{"user": users}
+}
+
+// 📸 Benchmark: snapshot + escritura con Clone vs ImmutableTreeMap
+func BenchmarkCloneSnapshot(b *testing.B) {
+	b.ReportAllocs()
+	m := goutils.NewTreeMap(benchUsers())
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		snap := m.Clone()
+		snap.Set("user."+strconv.Itoa(n%1000)+".active", true)
+	}
+}
+
+func BenchmarkImmutableSnapshot(b *testing.B) {
+	b.ReportAllocs()
+	m := goutils.NewImmutableTreeMap(benchUsers())
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		snap := m.Clone()
+		snap.Set("user."+strconv.Itoa(n%1000)+".active", true)
+	}
+}
+
+func benchConfig() map[string]any {
+	cfg := make(map[string]any, 32)
+	for i := 0; i < 32; i++ {
+		section := make(map[string]any, 16)
+		for j := 0; j < 16; j++ {
+			section["key"+strconv.Itoa(j)] = "value_" + strconv.Itoa(j)
+		}
+		cfg["section"+strconv.Itoa(i)] = section
+	}
+	return cfg
+}
+
+// 🔀 Benchmark: lecturas y escrituras concurrentes con 1% y 10% de escrituras,
+// sobre un mapa ancho ("user", 1000 claves) y un config de 32 secciones x 16
+// claves. Las lecturas no toman lock; cada escritura copia los mapas de su
+// ruta, así que el mapa ancho es el peor caso para SafeTreeMap. Medir con
+// -cpu 1,4,8 en una máquina con varios núcleos.
+func BenchmarkSafeTreeMapMixed(b *testing.B) {
+	for _, c := range mixedCases() {
+		b.Run(c.name, func(b *testing.B) {
+			safe := goutils.NewSyncTreeMap(c.data())
+			benchMixed(b, c.writes, c.key, func(key string, v string) {
+				safe.Set(key, v)
+			}, func(key string) {
+				_ = safe.Get(key).AsStringOr("")
+			})
+		})
+	}
+}
+
+// Referencia: TreeMap con un único sync.RWMutex
+func BenchmarkRWMutexTreeMapMixed(b *testing.B) {
+	for _, c := range mixedCases() {
+		b.Run(c.name, func(b *testing.B) {
+			m := goutils.NewTreeMap(c.data())
+			var mu sync.RWMutex
+			benchMixed(b, c.writes, c.key, func(key string, v string) {
+				mu.Lock()
+				m.Set(key, v)
+				mu.Unlock()
+			}, func(key string) {
+				mu.RLock()
+				_ = m.Get(key).AsStringOr("")
+				mu.RUnlock()
+			})
+		})
+	}
+}
+
+type mixedCase struct {
+	name   string
+	writes int
+	data   func() map[string]any
+	key    func(r *rand.Rand) string
+}
+
+func mixedCases() []mixedCase {
+	wide := func(r *rand.Rand) string { return "user." + strconv.Itoa(r.Intn(1000)) + ".name" }
+	config := func(r *rand.Rand) string {
+		return "section" + strconv.Itoa(r.Intn(32)) + ".key" + strconv.Itoa(r.Intn(16))
+	}
+	var cases []mixedCase
+	for _, writes := range []int{1, 10} {
+		cases = append(cases,
+			mixedCase{fmt.Sprintf("wide/writes=%d%%", writes), writes, benchUsers, wide},
+			mixedCase{fmt.Sprintf("config/writes=%d%%", writes), writes, benchConfig, config},
+		)
+	}
+	return cases
+}
+
+func benchMixed(b *testing.B, writes int, key func(*rand.Rand) string, write func(string, string), read func(string)) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			k := key(r)
+			if r.Intn(100) < writes {
+				write(k, "v"+strconv.Itoa(r.Intn(10)))
+			} else {
+				read(k)
+			}
+		}
+	})
+}
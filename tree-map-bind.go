@@ -0,0 +1,193 @@
+package goutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ------------------- Struct Binding -------------------
+
+// ErrRequired is reported for `required` fields whose path is missing or null.
+var ErrRequired = errors.New("required value missing")
+
+type FieldError struct {
+	Path  string // full path of the value in the tree
+	Field string // Go field name, dotted for nested structs
+	Err   error  // ErrRequired, a *ConversionError or a path error
+}
+
+func (e FieldError) Error() string {
+	var cerr *ConversionError
+	if errors.As(e.Err, &cerr) {
+		return fmt.Sprintf("%s (%s): cannot convert %T to %s: %v", displayPath(e.Path), e.Field, cerr.Value, cerr.Type, cerr.Err)
+	}
+	return fmt.Sprintf("%s (%s): %v", displayPath(e.Path), e.Field, e.Err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindError lists every field Bind could not fill.
+type BindError struct {
+	Fields []FieldError
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return "bind failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *BindError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Bind fills the struct pointed to by target from `tm` tagged fields:
+//
+//	Host string `tm:"db.primary.host,required,default=localhost"`
+//
+// Paths are relative to the tree, or to the parent path for nested structs
+// that carry tm tags themselves. Values are converted like GetAs; defaults
+// are parsed as strings and apply when the path is missing or null.
+// Untagged fields are left alone. Every failure is collected in a *BindError.
+func (d *TreeMap) Bind(target any) error {
+	if d.err != nil {
+		return d.err
+	}
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	b := &binder{tree: d}
+	b.bindStruct(rv.Elem(), "", "")
+	if len(b.out) == 0 {
+		return nil
+	}
+	return &BindError{Fields: b.out}
+}
+
+type binder struct {
+	tree *TreeMap
+	out  []FieldError
+}
+
+type bindTag struct {
+	path       string
+	required   bool
+	def        string
+	hasDefault bool
+}
+
+// parseBindTag splits `path,required,default=...`; the default runs to the
+// end of the tag so it may contain commas.
+func parseBindTag(tag string) bindTag {
+	path, rest, _ := strings.Cut(tag, ",")
+	out := bindTag{path: path}
+	for rest != "" {
+		if def, ok := strings.CutPrefix(rest, "default="); ok {
+			out.def, out.hasDefault = def, true
+			break
+		}
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		if opt == "required" {
+			out.required = true
+		}
+	}
+	return out
+}
+
+func (b *binder) bindStruct(v reflect.Value, base, fieldBase string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		tag, tagged := field.Tag.Lookup("tm")
+		if !tagged {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				b.bindStruct(fv, base, fieldBase)
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		opts := parseBindTag(tag)
+		if opts.path == "" {
+			opts.path = field.Name
+		}
+		path := joinRawPath(base, opts.path)
+		name := field.Name
+		if fieldBase != "" {
+			name = fieldBase + "." + field.Name
+		}
+
+		if st := field.Type; hasBindTags(st) || (st.Kind() == reflect.Pointer && hasBindTags(st.Elem())) {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(st.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			b.bindStruct(fv, path, name)
+			continue
+		}
+		b.bindField(fv, path, name, opts)
+	}
+}
+
+func (b *binder) bindField(fv reflect.Value, path, name string, opts bindTag) {
+	full := joinRawPath(b.tree.Path(), path)
+	if _, err := parseConcretePath(path); err != nil {
+		b.out = append(b.out, FieldError{Path: full, Field: name, Err: err})
+		return
+	}
+
+	node := b.tree.Get(path)
+	value := node.getValue()
+	if err := node.Err(); err != nil && !isMissing(err) {
+		b.out = append(b.out, FieldError{Path: full, Field: name, Err: err})
+		return
+	}
+	if value == nil {
+		switch {
+		case opts.hasDefault:
+			value = opts.def
+		case opts.required:
+			b.out = append(b.out, FieldError{Path: full, Field: name, Err: ErrRequired})
+			return
+		default:
+			return
+		}
+	}
+	if err := convertInto(b.tree.converter(), fv, value, full); err != nil {
+		b.out = append(b.out, FieldError{Path: full, Field: name, Err: err})
+	}
+}
+
+// hasBindTags reports whether t is a struct with at least one tm tag.
+func hasBindTags(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("tm"); ok {
+			return true
+		}
+	}
+	return false
+}
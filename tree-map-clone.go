@@ -9,6 +9,7 @@ func (d *TreeMap) Clone() TreeMapImpl {
 	return &TreeMap{
 		value: deepClone(d.value),
 		root:  d.root,
+		path:  d.path,
 	}
 }
 
@@ -21,22 +22,25 @@ func deepClone(v any) any {
 	case reflect.Map:
 		newMap := reflect.MakeMap(rv.Type())
 		for _, key := range rv.MapKeys() {
-			newMap.SetMapIndex(key, reflect.ValueOf(deepClone(rv.MapIndex(key).Interface())))
+			newMap.SetMapIndex(key, cloneValue(rv.MapIndex(key), rv.Type().Elem()))
 		}
 		return newMap.Interface()
 	case reflect.Slice:
 		newSlice := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
 		for i := 0; i < rv.Len(); i++ {
-			newSlice.Index(i).Set(reflect.ValueOf(deepClone(rv.Index(i).Interface())))
+			newSlice.Index(i).Set(cloneValue(rv.Index(i), rv.Type().Elem()))
 		}
 		return newSlice.Interface()
 	case reflect.Array:
 		newArray := reflect.New(rv.Type()).Elem()
 		for i := 0; i < rv.Len(); i++ {
-			newArray.Index(i).Set(reflect.ValueOf(deepClone(rv.Index(i).Interface())))
+			newArray.Index(i).Set(cloneValue(rv.Index(i), rv.Type().Elem()))
 		}
 		return newArray.Interface()
 	case reflect.Ptr:
+		if rv.IsNil() {
+			return v
+		}
 		val := reflect.New(rv.Elem().Type())
 		val.Elem().Set(reflect.ValueOf(deepClone(rv.Elem().Interface())))
 		return val.Interface()
@@ -44,3 +48,13 @@ func deepClone(v any) any {
 		return v
 	}
 }
+
+// cloneValue deep clones an element, keeping nil elements as the zero value
+// of elemType instead of an invalid reflect.Value.
+func cloneValue(v reflect.Value, elemType reflect.Type) reflect.Value {
+	cloned := deepClone(v.Interface())
+	if cloned == nil {
+		return reflect.Zero(elemType)
+	}
+	return reflect.ValueOf(cloned)
+}
@@ -0,0 +1,274 @@
+package goutils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ------------------- Type Coercion -------------------
+
+type CoercionMode int
+
+const (
+	// CoerceDefault keeps the historical TreeMap rules: floats truncate to
+	// ints, strings must parse exactly and only bools and true/false strings
+	// are booleans.
+	CoerceDefault CoercionMode = iota
+	// CoerceLenient also reads 0/1 and yes/no/on/off as booleans, booleans
+	// as 0/1, fractional strings as truncated ints, and trims spaces around
+	// numeric strings.
+	CoerceLenient
+	// CoerceStrict refuses lossy conversions: fractional or imprecise
+	// numbers, and booleans from anything but bools and true/false strings.
+	CoerceStrict
+)
+
+// ErrLossyConversion is wrapped by a *PathError when CoerceStrict refuses a
+// conversion that would lose information.
+var ErrLossyConversion = errors.New("lossy conversion")
+
+// errNotConvertible is turned into an *ErrTypeMismatch by the accessors.
+var errNotConvertible = errors.New("not convertible")
+
+// Converter holds the coercion mode and custom conversions used by AsString,
+// AsInt, AsFloat, AsBool and GetAs. Attach one to a tree with WithConverter;
+// trees without one use DefaultConverter.
+type Converter struct {
+	mode  CoercionMode
+	mu    sync.RWMutex
+	funcs map[reflect.Type][]func(any) (any, bool, error)
+}
+
+// DefaultConverter is used by trees without their own Converter.
+var DefaultConverter = NewConverter(CoerceDefault)
+
+func NewConverter(mode CoercionMode) *Converter {
+	return &Converter{mode: mode, funcs: map[reflect.Type][]func(any) (any, bool, error){}}
+}
+
+func (c *Converter) Mode() CoercionMode {
+	return c.mode
+}
+
+// RegisterConversion adds a conversion to T that runs before the built-in
+// rules. fn reports ok=false to pass values it does not handle.
+//
+//	goutils.RegisterConversion(conv, func(v any) (bool, bool, error) {
+//		switch v {
+//		case "yes":
+//			return true, true, nil
+//		case "no":
+//			return false, true, nil
+//		}
+//		return false, false, nil
+//	})
+func RegisterConversion[T any](c *Converter, fn func(v any) (T, bool, error)) *Converter {
+	t := reflect.TypeFor[T]()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.funcs[t] = append(c.funcs[t], func(v any) (any, bool, error) {
+		return fn(v)
+	})
+	return c
+}
+
+// custom runs the registered conversions to t, most recent first.
+func (c *Converter) custom(v any, t reflect.Type) (any, bool, error) {
+	c.mu.RLock()
+	funcs := c.funcs[t]
+	c.mu.RUnlock()
+	for i := len(funcs) - 1; i >= 0; i-- {
+		if out, ok, err := funcs[i](v); ok || err != nil {
+			return out, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+var (
+	stringType  = reflect.TypeFor[string]()
+	int64Type   = reflect.TypeFor[int64]()
+	float64Type = reflect.TypeFor[float64]()
+	boolType    = reflect.TypeFor[bool]()
+)
+
+func (c *Converter) toString(v any) (string, error) {
+	if out, ok, err := c.custom(v, stringType); ok {
+		return out.(string), err
+	}
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case json.Number:
+		return t.String(), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	}
+	if isNumber(v) {
+		return fmt.Sprint(v), nil
+	}
+	return "", errNotConvertible
+}
+
+func (c *Converter) toInt(v any) (int64, error) {
+	if out, ok, err := c.custom(v, int64Type); ok {
+		return out.(int64), err
+	}
+	switch t := v.(type) {
+	case string:
+		s := t
+		if c.mode == CoerceLenient {
+			s = strings.TrimSpace(s)
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil && c.mode == CoerceLenient {
+			if f, ferr := strconv.ParseFloat(s, 64); ferr == nil {
+				return c.floatToInt(f)
+			}
+		}
+		return n, err
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return c.floatToInt(f)
+	case float32:
+		return c.floatToInt(float64(t))
+	case float64:
+		return c.floatToInt(t)
+	case bool:
+		if c.mode == CoerceLenient {
+			if t {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return 0, errNotConvertible
+	}
+	if isNumber(v) {
+		return toInt64(reflect.ValueOf(v))
+	}
+	return 0, errNotConvertible
+}
+
+func (c *Converter) floatToInt(f float64) (int64, error) {
+	if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, ErrOutOfRange
+	}
+	if c.mode == CoerceStrict && f != math.Trunc(f) {
+		return 0, ErrLossyConversion
+	}
+	return int64(f), nil
+}
+
+// maxExactFloat is the largest integer magnitude a float64 holds exactly.
+const maxExactFloat = 1 << 53
+
+func (c *Converter) toFloat(v any) (float64, error) {
+	if out, ok, err := c.custom(v, float64Type); ok {
+		return out.(float64), err
+	}
+	switch t := v.(type) {
+	case string:
+		s := t
+		if c.mode == CoerceLenient {
+			s = strings.TrimSpace(s)
+		}
+		return strconv.ParseFloat(s, 64)
+	case json.Number:
+		return t.Float64()
+	case bool:
+		if c.mode == CoerceLenient {
+			if t {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return 0, errNotConvertible
+	}
+	if !isNumber(v) {
+		return 0, errNotConvertible
+	}
+	rv := reflect.ValueOf(v)
+	if c.mode == CoerceStrict {
+		if (rv.CanInt() && (rv.Int() > maxExactFloat || rv.Int() < -maxExactFloat)) ||
+			(rv.CanUint() && rv.Uint() > maxExactFloat) {
+			return 0, ErrLossyConversion
+		}
+	}
+	return toFloat64(rv), nil
+}
+
+func (c *Converter) toBool(v any) (bool, error) {
+	if out, ok, err := c.custom(v, boolType); ok {
+		return out.(bool), err
+	}
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		if c.mode != CoerceLenient {
+			return strconv.ParseBool(t)
+		}
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "1", "t", "true", "y", "yes", "on":
+			return true, nil
+		case "0", "f", "false", "n", "no", "off":
+			return false, nil
+		}
+		return false, fmt.Errorf("invalid boolean %q", t)
+	}
+	if c.mode == CoerceLenient && isNumber(v) {
+		switch f := toFloat64(reflect.ValueOf(unwrapNumber(v))); f {
+		case 0:
+			return false, nil
+		case 1:
+			return true, nil
+		}
+		return false, fmt.Errorf("invalid boolean %v", v)
+	}
+	return false, errNotConvertible
+}
+
+// ------------------- Tree Wiring -------------------
+
+// WithConverter makes every node of this tree convert with c.
+func (d *TreeMap) WithConverter(c *Converter) TreeMapImpl {
+	if root, ok := d.root.(*TreeMap); ok {
+		root.conv = c
+	} else {
+		d.conv = c
+	}
+	return d
+}
+
+func (d *TreeMap) converter() *Converter {
+	if root, ok := d.root.(*TreeMap); ok && root.conv != nil {
+		return root.conv
+	}
+	if d.conv != nil {
+		return d.conv
+	}
+	return DefaultConverter
+}
+
+// convertResult turns a Converter error into the accessor error for d.
+func (d *TreeMap) convertResult(want string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, errNotConvertible) {
+		return mismatch(d.Path(), want, d.value)
+	}
+	return d.valueError(err)
+}
@@ -0,0 +1,194 @@
+package goutils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ------------------- Compare -------------------
+
+type ChangeType string
+
+const (
+	ChangeAdded       ChangeType = "added"
+	ChangeRemoved     ChangeType = "removed"
+	ChangeModified    ChangeType = "modified"
+	ChangeTypeChanged ChangeType = "type-changed"
+)
+
+type Change struct {
+	Type ChangeType
+	Path string
+	Old  any
+	New  any
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("%s %s: %v", c.Type, c.Path, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s %s: %v", c.Type, c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s %s: %v (%T) -> %v (%T)", c.Type, c.Path, c.Old, c.Old, c.New, c.New)
+	}
+}
+
+type CompareOptions struct {
+	// LooseNumbers treats numbers of different Go types as equal when they
+	// hold the same value, e.g. int 5 and float64 5.0 from JSON.
+	LooseNumbers bool
+}
+
+// Compare returns the changes that turn a into b, ordered by path. Map keys
+// are compared by name and slices index by index.
+func Compare(a, b TreeMapImpl, opts ...CompareOptions) []Change {
+	var o CompareOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	var changes []Change
+	compareValues(nil, a.getValue(), b.getValue(), o, &changes)
+	return changes
+}
+
+func compareValues(segs []pathSegment, a, b any, opts CompareOptions, out *[]Change) {
+	child := func(seg pathSegment) []pathSegment {
+		return append(segs[:len(segs):len(segs)], seg)
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			aval, inA := av[k]
+			bval, inB := bv[k]
+			switch {
+			case !inB:
+				*out = append(*out, Change{Type: ChangeRemoved, Path: formatPath(child(keySegment(k))), Old: aval})
+			case !inA:
+				*out = append(*out, Change{Type: ChangeAdded, Path: formatPath(child(keySegment(k))), New: bval})
+			default:
+				compareValues(child(keySegment(k)), aval, bval, opts, out)
+			}
+		}
+		return
+
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(av), len(bv)); i++ {
+			switch {
+			case i >= len(bv):
+				*out = append(*out, Change{Type: ChangeRemoved, Path: formatPath(child(indexSegment(i))), Old: av[i]})
+			case i >= len(av):
+				*out = append(*out, Change{Type: ChangeAdded, Path: formatPath(child(indexSegment(i))), New: bv[i]})
+			default:
+				compareValues(child(indexSegment(i)), av[i], bv[i], opts, out)
+			}
+		}
+		return
+	}
+
+	if valuesEqual(a, b, opts.LooseNumbers) {
+		return
+	}
+	kind := ChangeModified
+	if !sameKind(a, b, opts.LooseNumbers) {
+		kind = ChangeTypeChanged
+	}
+	*out = append(*out, Change{Type: kind, Path: formatPath(segs), Old: a, New: b})
+}
+
+// sameKind reports whether a and b share a type. With looseNumbers every
+// numeric type counts as the same kind.
+func sameKind(a, b any, looseNumbers bool) bool {
+	if looseNumbers && isNumber(a) && isNumber(b) {
+		return true
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b)
+}
+
+// ------------------- Equality -------------------
+
+// valuesEqual compares two normalized values deeply. With looseNumbers any
+// two numeric values are equal when they hold the same number (int 5 == 5.0).
+func valuesEqual(a, b any, looseNumbers bool) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			w, ok := bv[k]
+			if !ok || !valuesEqual(v, w, looseNumbers) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !valuesEqual(av[i], bv[i], looseNumbers) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if looseNumbers && isNumber(a) && isNumber(b) {
+		return numbersEqual(a, b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// numbersEqual compares integers exactly and falls back to float64 otherwise.
+func numbersEqual(a, b any) bool {
+	ra, rb := reflect.ValueOf(unwrapNumber(a)), reflect.ValueOf(unwrapNumber(b))
+	switch {
+	case ra.CanInt() && rb.CanInt():
+		return ra.Int() == rb.Int()
+	case ra.CanUint() && rb.CanUint():
+		return ra.Uint() == rb.Uint()
+	case ra.CanInt() && rb.CanUint():
+		return ra.Int() >= 0 && uint64(ra.Int()) == rb.Uint()
+	case ra.CanUint() && rb.CanInt():
+		return rb.Int() >= 0 && ra.Uint() == uint64(rb.Int())
+	}
+	return toFloat64(ra) == toFloat64(rb)
+}
+
+func toFloat64(rv reflect.Value) float64 {
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int())
+	case rv.CanUint():
+		return float64(rv.Uint())
+	case rv.Kind() == reflect.String:
+		f, _ := strconv.ParseFloat(rv.String(), 64)
+		return f
+	default:
+		return rv.Float()
+	}
+}
@@ -2,71 +2,49 @@ package goutils
 
 import (
 	"encoding/json"
-	"fmt"
 	"reflect"
-	"strconv"
 )
 
 // ------------------- Value Conversions -------------------
+// Conversion rules come from the tree's Converter, see WithConverter.
 func (d *TreeMap) AsString() (string, error) {
-	switch v := d.value.(type) {
-	case string:
-		return v, nil
-	case int, int64, float64, bool:
-		return fmt.Sprint(v), nil
-	default:
-		return "", fmt.Errorf("cannot convert to string: %T", v)
+	if d.err != nil {
+		return "", d.err
 	}
+	s, err := d.converter().toString(d.value)
+	return s, d.convertResult("string", err)
 }
 
 func (d *TreeMap) AsInt() (int64, error) {
 	if d.err != nil {
 		return 0, d.err
 	}
-	switch v := d.value.(type) {
-	case float64:
-		return int64(v), nil
-	case int:
-		return int64(v), nil
-	case int64:
-		return v, nil
-	case string:
-		return strconv.ParseInt(v, 10, 64)
-	default:
-		return 0, fmt.Errorf("cannot convert to int: %T", v)
-	}
+	n, err := d.converter().toInt(d.value)
+	return n, d.convertResult("int", err)
 }
 
 func (d *TreeMap) AsFloat() (float64, error) {
 	if d.err != nil {
 		return 0, d.err
 	}
-	switch v := d.value.(type) {
-	case float64:
-		return v, nil
-	case int:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	case string:
-		return strconv.ParseFloat(v, 64)
-	default:
-		return 0, fmt.Errorf("cannot convert to float64: %T", v)
-	}
+	f, err := d.converter().toFloat(d.value)
+	return f, d.convertResult("float", err)
 }
 
 func (d *TreeMap) AsBool() (bool, error) {
 	if d.err != nil {
 		return false, d.err
 	}
-	switch v := d.value.(type) {
-	case bool:
-		return v, nil
-	case string:
-		return strconv.ParseBool(v)
-	default:
-		return false, fmt.Errorf("cannot convert to bool: %T", v)
+	b, err := d.converter().toBool(d.value)
+	return b, d.convertResult("bool", err)
+}
+
+// valueError attaches the node path to a parse or range error.
+func (d *TreeMap) valueError(err error) error {
+	if err == nil {
+		return nil
 	}
+	return &PathError{Path: d.Path(), Err: err}
 }
 
 func (d *TreeMap) AsAny() (any, error) {
@@ -146,7 +124,7 @@ func (d *TreeMap) AsMap() (DefaultMap, error) {
 	if m, ok := d.value.(map[string]any); ok {
 		return m, nil
 	}
-	return nil, fmt.Errorf("cannot convert to map: %T", d.value)
+	return nil, mismatch(d.Path(), "map", d.value)
 }
 
 func (d *TreeMap) AsSlice() ([]TreeMapImpl, error) {
@@ -156,12 +134,13 @@ func (d *TreeMap) AsSlice() ([]TreeMapImpl, error) {
 
 	rv := reflect.ValueOf(d.value)
 	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
-		return nil, fmt.Errorf("not a slice: %T", d.value)
+		return nil, mismatch(d.Path(), "slice", d.value)
 	}
 
 	var result []TreeMapImpl
 	for i := range rv.Len() {
-		result = append(result, &TreeMap{value: rv.Index(i).Interface(), root: d.root})
+		path := append(d.path[:len(d.path):len(d.path)], indexSegment(i))
+		result = append(result, &TreeMap{value: rv.Index(i).Interface(), root: d.root, path: path})
 	}
 	return result, nil
 }
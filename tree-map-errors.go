@@ -0,0 +1,69 @@
+package goutils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ------------------- Errors -------------------
+
+var (
+	// ErrNotFound is wrapped by a *PathError when a path does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrIndexOutOfRange is wrapped by a *PathError for bad slice indexes.
+	ErrIndexOutOfRange = errors.New("index out of range")
+)
+
+// PathError reports a lookup or write that failed at Path, the full path
+// from the root.
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s: %v", displayPath(e.Path), e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// ErrTypeMismatch reports a value at Path that is not of the expected type.
+// Got is the JSON type of the value ("string", "number", "null", ...).
+type ErrTypeMismatch struct {
+	Path string
+	Want string
+	Got  string
+}
+
+func (e *ErrTypeMismatch) Error() string {
+	return fmt.Sprintf("%s: expected %s, got %s", displayPath(e.Path), e.Want, e.Got)
+}
+
+func mismatch(path string, want string, value any) error {
+	return &ErrTypeMismatch{Path: path, Want: want, Got: jsonType(value)}
+}
+
+// prefixErrorPath makes a path error raised relative to a node absolute by
+// prepending the node's path. Other errors are returned unchanged.
+func prefixErrorPath(err error, prefix []pathSegment) error {
+	if len(prefix) == 0 {
+		return err
+	}
+	base := formatPath(prefix)
+	var perr *PathError
+	var terr *ErrTypeMismatch
+	switch {
+	case errors.As(err, &perr):
+		perr.Path = joinRawPath(base, perr.Path)
+	case errors.As(err, &terr):
+		terr.Path = joinRawPath(base, terr.Path)
+	}
+	return err
+}
+
+// isMissing reports whether err means the path does not exist.
+func isMissing(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrIndexOutOfRange)
+}
@@ -0,0 +1,505 @@
+package goutils
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ------------------- Filter Expressions -------------------
+//
+//	orders[?(@.total > 100 && @.status == "paid")].id
+//	users[?(!@.deleted)]
+//	items[?(@.sku =~ /^AB-/)]
+//	items[?(@.price <= $.limits.price)]
+//
+// Operands are `@` paths (current element), `$` paths (document root),
+// numbers, 'strings', "strings", true, false and null. Integers compare
+// exactly; other operands go through the tree's Converter, so "150" > 100
+// holds.
+
+type filterNode struct {
+	op          string // "||" "&&" "!" "==" "!=" "<" "<=" ">" ">=" "=~" "path" "lit"
+	left, right *filterNode
+	path        []pathSegment
+	fromRoot    bool
+	lit         any
+	re          *regexp.Regexp
+}
+
+func parseFilter(src string) (*filterNode, error) {
+	toks, err := lexFilter(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{src: src, toks: toks}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("invalid filter %q: unexpected %q", src, p.toks[p.pos].text)
+	}
+	return node, nil
+}
+
+// ------------------- Lexer -------------------
+
+type filterTokKind int
+
+const (
+	tokOp filterTokKind = iota
+	tokPath
+	tokLit
+	tokRegex
+)
+
+type filterTok struct {
+	kind filterTokKind
+	text string
+	lit  any
+}
+
+func lexFilter(src string) ([]filterTok, error) {
+	var toks []filterTok
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+
+		case strings.HasPrefix(src[i:], "&&"), strings.HasPrefix(src[i:], "||"),
+			strings.HasPrefix(src[i:], "=="), strings.HasPrefix(src[i:], "!="),
+			strings.HasPrefix(src[i:], "<="), strings.HasPrefix(src[i:], ">="),
+			strings.HasPrefix(src[i:], "=~"):
+			toks = append(toks, filterTok{kind: tokOp, text: src[i : i+2]})
+			i += 2
+
+		case strings.ContainsRune("()!<>", rune(c)):
+			toks = append(toks, filterTok{kind: tokOp, text: string(c)})
+			i++
+
+		case c == '@' || c == '$':
+			end := scanFilterPath(src, i+1)
+			toks = append(toks, filterTok{kind: tokPath, text: src[i:end]})
+			i = end
+
+		case c == '"' || c == '\'':
+			p := &pathParser{src: src, pos: i}
+			s, err := p.parseQuoted()
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: unterminated string", src)
+			}
+			toks = append(toks, filterTok{kind: tokLit, text: src[i:p.pos], lit: s})
+			i = p.pos
+
+		case c == '/':
+			end := i + 1
+			for end < len(src) && src[end] != '/' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("invalid filter %q: unterminated regex", src)
+			}
+			toks = append(toks, filterTok{kind: tokRegex, text: src[i+1 : end]})
+			i = end + 1
+
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(src) && strings.ContainsRune("0123456789.eE+-", rune(src[end])) {
+				end++
+			}
+			var lit any
+			if n, err := strconv.ParseInt(src[i:end], 10, 64); err == nil {
+				lit = n
+			} else if f, err := strconv.ParseFloat(src[i:end], 64); err == nil {
+				lit = f
+			} else {
+				return nil, fmt.Errorf("invalid filter %q: bad number %q", src, src[i:end])
+			}
+			toks = append(toks, filterTok{kind: tokLit, text: src[i:end], lit: lit})
+			i = end
+
+		default:
+			end := i
+			for end < len(src) && (isIdentByte(src[end])) {
+				end++
+			}
+			word := src[i:end]
+			switch word {
+			case "true":
+				toks = append(toks, filterTok{kind: tokLit, text: word, lit: true})
+			case "false":
+				toks = append(toks, filterTok{kind: tokLit, text: word, lit: false})
+			case "null":
+				toks = append(toks, filterTok{kind: tokLit, text: word, lit: nil})
+			default:
+				return nil, fmt.Errorf("invalid filter %q: unexpected %q at %d", src, c, i)
+			}
+			i = end
+		}
+	}
+	return toks, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// scanFilterPath returns the end of a `@...`/`$...` path starting at i.
+func scanFilterPath(src string, i int) int {
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src):
+			i += 2
+		case c == '.' || c == '-' || c == '*' || isIdentByte(c):
+			i++
+		case c == '[':
+			i, _ = skipBracket(src, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipBracket returns the index after the `]` matching the `[` at i,
+// skipping quoted strings and nested brackets/parens. ok is false when the
+// bracket is never closed.
+func skipBracket(src string, i int) (end int, ok bool) {
+	depth := 0
+	for i < len(src) {
+		switch c := src[i]; c {
+		case '"', '\'':
+			p := &pathParser{src: src, pos: i}
+			if _, err := p.parseQuoted(); err != nil {
+				return len(src), false
+			}
+			i = p.pos
+			continue
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+			if depth == 0 {
+				return i + 1, c == ']'
+			}
+		}
+		i++
+	}
+	return len(src), false
+}
+
+// ------------------- Parser -------------------
+
+type filterParser struct {
+	src  string
+	toks []filterTok
+	pos  int
+}
+
+func (p *filterParser) peekOp(ops ...string) string {
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == tokOp {
+		for _, op := range ops {
+			if p.toks[p.pos].text == op {
+				return op
+			}
+		}
+	}
+	return ""
+}
+
+func (p *filterParser) parseOr() (*filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") != "" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (*filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") != "" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (*filterNode, error) {
+	if p.peekOp("!") != "" {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNode{op: "!", left: inner}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (*filterNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peekOp("==", "!=", "<", "<=", ">", ">=", "=~")
+	if op == "" {
+		return left, nil
+	}
+	p.pos++
+
+	if op == "=~" {
+		if p.pos >= len(p.toks) || (p.toks[p.pos].kind != tokRegex && p.toks[p.pos].kind != tokLit) {
+			return nil, fmt.Errorf("invalid filter %q: =~ expects /regex/ or a string", p.src)
+		}
+		pattern := p.toks[p.pos].text
+		if s, ok := p.toks[p.pos].lit.(string); ok {
+			pattern = s
+		}
+		p.pos++
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", p.src, err)
+		}
+		return &filterNode{op: op, left: left, re: re}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return &filterNode{op: op, left: left, right: right}, nil
+}
+
+func (p *filterParser) parseOperand() (*filterNode, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("invalid filter %q: unexpected end", p.src)
+	}
+	tok := p.toks[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case tokLit:
+		return &filterNode{op: "lit", lit: tok.lit}, nil
+
+	case tokPath:
+		rel := strings.TrimPrefix(tok.text[1:], ".")
+		node := &filterNode{op: "path", fromRoot: tok.text[0] == '$'}
+		if rel != "" {
+			segs, err := parseConcretePath(rel)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", p.src, err)
+			}
+			node.path = segs
+		}
+		return node, nil
+
+	case tokOp:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if p.peekOp(")") == "" {
+				return nil, fmt.Errorf("invalid filter %q: missing ')'", p.src)
+			}
+			p.pos++
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid filter %q: unexpected %q", p.src, tok.text)
+}
+
+// ------------------- Evaluation -------------------
+
+// filterEnv is what a filter sees besides the current element.
+type filterEnv struct {
+	root any        // what `$` refers to
+	conv *Converter // coercion rules of the queried tree
+}
+
+// coerce wraps v so the Converter accessors apply the tree's rules.
+func (env filterEnv) coerce(v any) *TreeMap {
+	return &TreeMap{value: v, conv: env.conv}
+}
+
+func (n *filterNode) matches(current any, env filterEnv) bool {
+	v, ok := n.eval(current, env)
+	return ok && truthy(v)
+}
+
+// eval returns the operand value and whether it exists.
+func (n *filterNode) eval(current any, env filterEnv) (any, bool) {
+	switch n.op {
+	case "lit":
+		return n.lit, true
+	case "path":
+		base := current
+		if n.fromRoot {
+			base = env.root
+		}
+		for _, seg := range n.path {
+			if m, ok := base.(map[string]any); ok {
+				if _, exists := m[seg.key]; !exists {
+					return nil, false
+				}
+			}
+			next, _, err := stepInto(base, seg)
+			if err != nil {
+				return nil, false
+			}
+			base = next
+		}
+		return base, true
+	case "!":
+		return !n.left.matches(current, env), true
+	case "&&":
+		return n.left.matches(current, env) && n.right.matches(current, env), true
+	case "||":
+		return n.left.matches(current, env) || n.right.matches(current, env), true
+	case "=~":
+		v, ok := n.left.eval(current, env)
+		if !ok {
+			return false, true
+		}
+		s, err := env.coerce(v).AsString()
+		return err == nil && n.re.MatchString(s), true
+	}
+
+	l, lok := n.left.eval(current, env)
+	r, rok := n.right.eval(current, env)
+	if !lok || !rok {
+		return n.op == "!=" && lok != rok, true
+	}
+	switch n.op {
+	case "==":
+		return filterEqual(l, r, env), true
+	case "!=":
+		return !filterEqual(l, r, env), true
+	default:
+		cmp, ok := filterCompare(l, r, env)
+		if !ok {
+			return false, true
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, true
+		case "<=":
+			return cmp <= 0, true
+		case ">":
+			return cmp > 0, true
+		default:
+			return cmp >= 0, true
+		}
+	}
+}
+
+func truthy(v any) bool {
+	switch b := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return b
+	}
+	return true
+}
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return true
+	}
+	return false
+}
+
+func filterEqual(l, r any, env filterEnv) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	if isNumber(l) || isNumber(r) {
+		c, ok := filterCompare(l, r, env)
+		return ok && c == 0
+	}
+	if lb, ok := l.(bool); ok {
+		rb, err := env.coerce(r).AsBool()
+		return err == nil && lb == rb
+	}
+	if rb, ok := r.(bool); ok {
+		lb, err := env.coerce(l).AsBool()
+		return err == nil && lb == rb
+	}
+	ls, lerr := env.coerce(l).AsString()
+	rs, rerr := env.coerce(r).AsString()
+	if lerr == nil && rerr == nil {
+		return ls == rs
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+// filterCompare orders two operands numerically when both are integers or
+// coerce to float, otherwise as strings. Integers are compared as integers,
+// so values above 2^53 keep their order.
+func filterCompare(l, r any, env filterEnv) (int, bool) {
+	if c, ok := compareIntegers(l, r); ok {
+		return c, true
+	}
+	lf, lerr := env.coerce(l).AsFloat()
+	rf, rerr := env.coerce(r).AsFloat()
+	if lerr == nil && rerr == nil {
+		return cmp.Compare(lf, rf), true
+	}
+	ls, lok := l.(string)
+	rs, rok := r.(string)
+	if lok && rok {
+		return strings.Compare(ls, rs), true
+	}
+	return 0, false
+}
+
+// compareIntegers orders l and r when both are integers, mixing signed and
+// unsigned like numbersEqual.
+func compareIntegers(l, r any) (int, bool) {
+	rl, rr := reflect.ValueOf(unwrapNumber(l)), reflect.ValueOf(unwrapNumber(r))
+	switch {
+	case rl.CanInt() && rr.CanInt():
+		return cmp.Compare(rl.Int(), rr.Int()), true
+	case rl.CanUint() && rr.CanUint():
+		return cmp.Compare(rl.Uint(), rr.Uint()), true
+	case rl.CanInt() && rr.CanUint():
+		if rl.Int() < 0 {
+			return -1, true
+		}
+		return cmp.Compare(uint64(rl.Int()), rr.Uint()), true
+	case rl.CanUint() && rr.CanInt():
+		if rr.Int() < 0 {
+			return 1, true
+		}
+		return cmp.Compare(rl.Uint(), uint64(rr.Int())), true
+	}
+	return 0, false
+}
@@ -0,0 +1,181 @@
+package goutils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ------------------- Flatten / Unflatten -------------------
+
+type IndexStyle int
+
+const (
+	// IndexDot renders slice indexes as keys: servers.0.port
+	IndexDot IndexStyle = iota
+	// IndexBracket renders slice indexes in brackets: servers[0].port
+	IndexBracket
+)
+
+// FlattenOptions configures Flatten and Unflatten. Keys that contain the
+// separator cannot be told apart from nested keys.
+type FlattenOptions struct {
+	Separator  string // defaults to "."
+	IndexStyle IndexStyle
+	MaxDepth   int // 0 means unlimited, deeper subtrees are kept as values
+}
+
+func flattenOptions(opts []FlattenOptions) FlattenOptions {
+	var o FlattenOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Separator == "" {
+		o.Separator = "."
+	}
+	return o
+}
+
+// Flatten returns one entry per leaf keyed by its joined path, e.g.
+// {"db.host": "x", "servers.0.port": 80}. Empty maps and slices are kept as
+// values so Unflatten can rebuild them. A scalar tree flattens to an empty map.
+func (d *TreeMap) Flatten(opts ...FlattenOptions) DefaultMap {
+	out := make(DefaultMap)
+	if d.err != nil {
+		return out
+	}
+	o := flattenOptions(opts)
+	for _, c := range childrenOf(d.value) {
+		flattenInto(out, o.childKey("", c.seg), c.value, 1, o)
+	}
+	return out
+}
+
+func flattenInto(out DefaultMap, key string, value any, depth int, o FlattenOptions) {
+	children := childrenOf(value)
+	if len(children) == 0 || (o.MaxDepth > 0 && depth >= o.MaxDepth) {
+		out[key] = deepClone(value)
+		return
+	}
+	for _, c := range children {
+		flattenInto(out, o.childKey(key, c.seg), c.value, depth+1, o)
+	}
+}
+
+func (o FlattenOptions) childKey(prefix string, seg pathSegment) string {
+	part := seg.key
+	if seg.kind == segmentIndex {
+		part = strconv.Itoa(seg.index)
+		if o.IndexStyle == IndexBracket {
+			return prefix + "[" + part + "]"
+		}
+	}
+	if prefix == "" {
+		return part
+	}
+	return prefix + o.Separator + part
+}
+
+// flatNode marks containers built by Unflatten, as opposed to map values
+// that were stored in the flat map as-is.
+type flatNode map[string]any
+
+// Unflatten rebuilds a tree from Flatten output. Maps whose keys are exactly
+// 0..n-1 become slices. Conflicting keys such as "a" and "a.b" (with a
+// scalar "a") return an error tree.
+func Unflatten(flat map[string]any, opts ...FlattenOptions) TreeMapImpl {
+	o := flattenOptions(opts)
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	root := flatNode{}
+	for _, key := range keys {
+		parts := o.splitKey(key)
+		current := root
+		for _, part := range parts[:len(parts)-1] {
+			next, exists := current[part]
+			if !exists {
+				next = flatNode{}
+				current[part] = next
+			}
+			node, ok := next.(flatNode)
+			if !ok {
+				return &TreeMap{err: fmt.Errorf("unflatten: key %q conflicts with a value at %q", key, part)}
+			}
+			current = node
+		}
+		last := parts[len(parts)-1]
+		if _, exists := current[last]; exists {
+			return &TreeMap{err: fmt.Errorf("unflatten: key %q conflicts with another key", key)}
+		}
+		current[last] = normalizeToDefault(flat[key])
+	}
+	return NewTreeMap(buildFlatNode(root))
+}
+
+// splitKey splits a flat key on the separator and, with IndexBracket,
+// on trailing [n] indexes.
+func (o FlattenOptions) splitKey(key string) []string {
+	parts := strings.Split(key, o.Separator)
+	if o.IndexStyle != IndexBracket {
+		return parts
+	}
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		var indexes []string
+		for strings.HasSuffix(part, "]") {
+			open := strings.LastIndexByte(part, '[')
+			if open < 0 {
+				break
+			}
+			indexes = append(indexes, part[open+1:len(part)-1])
+			part = part[:open]
+		}
+		if part != "" || len(indexes) == 0 {
+			out = append(out, part)
+		}
+		for i := len(indexes) - 1; i >= 0; i-- {
+			out = append(out, indexes[i])
+		}
+	}
+	return out
+}
+
+func buildFlatNode(v any) any {
+	node, ok := v.(flatNode)
+	if !ok {
+		return v
+	}
+	if list, ok := node.asSlice(); ok {
+		for i, e := range list {
+			list[i] = buildFlatNode(e)
+		}
+		return list
+	}
+	out := make(map[string]any, len(node))
+	for k, e := range node {
+		out[k] = buildFlatNode(e)
+	}
+	return out
+}
+
+// asSlice reports whether the keys are exactly "0".."n-1".
+func (n flatNode) asSlice() ([]any, bool) {
+	if len(n) == 0 {
+		return nil, false
+	}
+	list := make([]any, len(n))
+	for k, v := range n {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(n) || strconv.Itoa(i) != k {
+			return nil, false
+		}
+		list[i] = v
+	}
+	return list, true
+}
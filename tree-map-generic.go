@@ -0,0 +1,432 @@
+package goutils
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ------------------- Generic Accessors -------------------
+
+// ErrOutOfRange is wrapped by a ConversionError when a number does not fit
+// the target type, e.g. 300 into uint8.
+var ErrOutOfRange = errors.New("value out of range")
+
+// ConversionError reports a value at Path that could not be converted to Type.
+type ConversionError struct {
+	Path  string
+	Type  reflect.Type
+	Value any
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("cannot convert %s (%T) to %s: %v", displayPath(e.Path), e.Value, e.Type, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// As lets errors.As find an *ErrTypeMismatch when the value has the wrong
+// type altogether, as the AsInt/AsString accessors report it.
+func (e *ConversionError) As(target any) bool {
+	tm, ok := target.(**ErrTypeMismatch)
+	if !ok || !isTypeMismatch(e.Err) {
+		return false
+	}
+	*tm = &ErrTypeMismatch{Path: e.Path, Want: e.Type.String(), Got: jsonType(e.Value)}
+	return true
+}
+
+var (
+	errNotNumber   = errors.New("not a number")
+	errUnsupported = errors.New("unsupported conversion")
+)
+
+func isTypeMismatch(err error) bool {
+	return errors.Is(err, errNotNumber) || errors.Is(err, errUnsupported) || errors.Is(err, errNotConvertible)
+}
+
+// GetAs reads path and converts it to T by reflection: numbers with range
+// checks, strings, bools, time.Time, time.Duration, slices, arrays, maps,
+// structs (json tag names), pointers and encoding.TextUnmarshaler types.
+// Numbers and bools follow the tree's Converter, like AsInt and AsBool.
+//
+//	timeout, err := goutils.GetAs[time.Duration](tree, "http.timeout")
+//	items, err := goutils.GetAs[[]Item](tree, "items")
+func GetAs[T any](tree TreeMapImpl, path string) (T, error) {
+	return As[T](tree.Get(path))
+}
+
+// GetAsOr is GetAs with a fallback for missing or unconvertible values.
+func GetAsOr[T any](tree TreeMapImpl, path string, def T) T {
+	v, err := GetAs[T](tree, path)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// MustGet is GetAs that panics on error.
+func MustGet[T any](tree TreeMapImpl, path string) T {
+	v, err := GetAs[T](tree, path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// As converts the value held by node to T, see GetAs.
+func As[T any](node TreeMapImpl) (T, error) {
+	var zero T
+	if err := node.Err(); err != nil {
+		return zero, err
+	}
+
+	out := reflect.New(reflect.TypeFor[T]()).Elem()
+	if err := convertInto(node.converter(), out, detachedValue(node), node.Path()); err != nil {
+		return zero, err
+	}
+	return out.Interface().(T), nil
+}
+
+// detachedValue returns the value of node, copied when node belongs to an
+// immutable or synchronized tree: convertInto stores maps and slices as they
+// are into `any` targets, and those must not alias a shared version.
+func detachedValue(node TreeMapImpl) any {
+	v := node.getValue()
+	switch node.(type) {
+	case *ImmutableTreeMap, *SafeTreeMap:
+		if isContainer(v) {
+			return deepClone(v)
+		}
+	}
+	return v
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	timeType            = reflect.TypeFor[time.Time]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// convertInto stores v into dst (a settable value), converting as needed.
+// Conversions registered on c for the exact target type run first.
+func convertInto(c *Converter, dst reflect.Value, v any, path string) error {
+	t := dst.Type()
+	fail := func(err error) error {
+		return &ConversionError{Path: path, Type: t, Value: v, Err: err}
+	}
+
+	if out, ok, err := c.custom(v, t); ok {
+		if err != nil {
+			return fail(err)
+		}
+		dst.Set(reflect.ValueOf(out))
+		return nil
+	}
+
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			dst.SetZero()
+			return nil
+		}
+		return fail(errors.New("no value"))
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) && t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+		dst.Set(rv)
+		return nil
+	}
+
+	switch t {
+	case durationType:
+		d, err := toDuration(v)
+		if err != nil {
+			return fail(err)
+		}
+		dst.SetInt(int64(d))
+		return nil
+	case timeType:
+		tm, err := parseTime(v, nil)
+		if err != nil {
+			return fail(err)
+		}
+		dst.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	if s, ok := v.(string); ok && reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fail(err)
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		switch rv.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			dst.SetString(fmt.Sprint(v))
+			return nil
+		}
+
+	case reflect.Bool:
+		b, err := c.toBool(v)
+		if err != nil {
+			return fail(err)
+		}
+		dst.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := c.toInt(plainNumber(rv))
+		if err != nil {
+			return fail(rangeError(err))
+		}
+		if dst.OverflowInt(n) {
+			return fail(ErrOutOfRange)
+		}
+		dst.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if u, ok := unwrapNumber(plainNumber(rv)).(uint64); ok {
+			n = u
+		} else {
+			i, err := c.toInt(plainNumber(rv))
+			if err != nil {
+				return fail(rangeError(err))
+			}
+			if i < 0 {
+				return fail(ErrOutOfRange)
+			}
+			n = uint64(i)
+		}
+		if dst.OverflowUint(n) {
+			return fail(ErrOutOfRange)
+		}
+		dst.SetUint(n)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		f, err := c.toFloat(plainNumber(rv))
+		if err != nil {
+			return fail(rangeError(err))
+		}
+		if dst.OverflowFloat(f) {
+			return fail(ErrOutOfRange)
+		}
+		dst.SetFloat(f)
+		return nil
+
+	case reflect.Pointer:
+		elem := reflect.New(t.Elem())
+		if err := convertInto(c, elem.Elem(), v, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+
+	case reflect.Interface:
+		if rv.Type().Implements(t) {
+			dst.Set(rv)
+			return nil
+		}
+
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			break
+		}
+		n := rv.Len()
+		if t.Kind() == reflect.Array {
+			if n != t.Len() {
+				return fail(fmt.Errorf("expected %d elements, got %d", t.Len(), n))
+			}
+		} else {
+			dst.Set(reflect.MakeSlice(t, n, n))
+		}
+		for i := 0; i < n; i++ {
+			if err := convertInto(c, dst.Index(i), rv.Index(i).Interface(), joinPath(path, indexSegment(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String || t.Key().Kind() != reflect.String {
+			break
+		}
+		out := reflect.MakeMapWithSize(t, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			elem := reflect.New(t.Elem()).Elem()
+			if err := convertInto(c, elem, iter.Value().Interface(), joinPath(path, keySegment(key))); err != nil {
+				return err
+			}
+			out.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+		dst.Set(out)
+		return nil
+
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			break
+		}
+		return convertStruct(c, dst, m, path)
+	}
+
+	return fail(errUnsupported)
+}
+
+// convertStruct fills exported fields by json tag name, falling back to a
+// case-insensitive match on the field name like encoding/json.
+func convertStruct(c *Converter, dst reflect.Value, m map[string]any, path string) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := convertStruct(c, dst.Field(i), m, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		key, found := name, false
+		if _, ok := m[name]; ok {
+			found = true
+		} else {
+			for k := range m {
+				if strings.EqualFold(k, name) {
+					key, found = k, true
+					break
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+		if err := convertInto(c, dst.Field(i), m[key], joinPath(path, keySegment(key))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toInt64(rv reflect.Value) (int64, error) {
+	switch {
+	case rv.CanInt():
+		return rv.Int(), nil
+	case rv.CanUint():
+		if rv.Uint() > math.MaxInt64 {
+			return 0, ErrOutOfRange
+		}
+		return int64(rv.Uint()), nil
+	case rv.CanFloat():
+		f := rv.Float()
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("%v has a fractional part", f)
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, ErrOutOfRange
+		}
+		return int64(f), nil
+	case rv.Kind() == reflect.String:
+		n, err := strconv.ParseInt(strings.TrimSpace(rv.String()), 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, ErrOutOfRange
+		}
+		return n, err
+	}
+	return 0, errNotNumber
+}
+
+// plainNumber returns values of named numeric types as int64, uint64 or
+// float64 so the Converter, which switches on built-in types, accepts them.
+func plainNumber(rv reflect.Value) any {
+	if v := rv.Interface(); isNumber(v) {
+		return v
+	}
+	switch {
+	case rv.CanInt():
+		return rv.Int()
+	case rv.CanUint():
+		return rv.Uint()
+	case rv.CanFloat():
+		return rv.Float()
+	}
+	return rv.Interface()
+}
+
+// rangeError reports strconv range failures as ErrOutOfRange.
+func rangeError(err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return ErrOutOfRange
+	}
+	return err
+}
+
+// toDuration returns a time.Duration unchanged and accepts Go duration
+// strings ("1m30s") and numbers of seconds.
+func toDuration(v any) (time.Duration, error) {
+	if d, ok := v.(time.Duration); ok {
+		return d, nil
+	}
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	if isNumber(v) {
+		return time.Duration(toFloat64(reflect.ValueOf(v)) * float64(time.Second)), nil
+	}
+	return 0, fmt.Errorf("cannot convert to duration: %T", v)
+}
+
+// joinPath appends one segment to a rendered path.
+func joinPath(base string, seg pathSegment) string {
+	if base == "" {
+		return formatPath([]pathSegment{seg})
+	}
+	if seg.kind == segmentKey && isPlainKey(seg.key) {
+		return base + "." + seg.key
+	}
+	return base + formatPath([]pathSegment{seg})
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
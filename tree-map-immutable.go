@@ -0,0 +1,543 @@
+package goutils
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"net/netip"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImmutableTreeMap is a persistent TreeMap: writes return a new version and
+// leave the receiver untouched. Set, Delete and their pointer variants copy
+// only the containers along the written path and share everything else with
+// the previous version, so Clone is O(1) and readers need no locks.
+// SetAll, DeleteAll, ApplyPatch and Merge copy the whole tree.
+//
+// Values handed out by AsMap, AsAny, AsAnySlice, As, GetAs and Bind are
+// copies.
+type ImmutableTreeMap struct {
+	tm *TreeMap
+}
+
+func NewImmutableTreeMap(data ...any) TreeMapImpl {
+	return &ImmutableTreeMap{tm: NewTreeMap(data...).(*TreeMap)}
+}
+
+func (d *ImmutableTreeMap) wrap(node TreeMapImpl) TreeMapImpl {
+	if tm, ok := node.(*TreeMap); ok {
+		return &ImmutableTreeMap{tm: tm}
+	}
+	return node
+}
+
+func (d *ImmutableTreeMap) fail(err error) TreeMapImpl {
+	return &ImmutableTreeMap{tm: &TreeMap{err: err}}
+}
+
+func (d *ImmutableTreeMap) rootTree() *TreeMap {
+	if root, ok := d.tm.root.(*TreeMap); ok {
+		return root
+	}
+	return d.tm
+}
+
+// commit returns the node at d's path in a new version rooted at value.
+func (d *ImmutableTreeMap) commit(value any) TreeMapImpl {
+	root := &TreeMap{value: value, conv: d.rootTree().conv}
+	root.root = root
+	return d.wrap(root.getSegments(d.tm.path))
+}
+
+// absolute prefixes segs, relative to d, with d's path.
+func (d *ImmutableTreeMap) absolute(segs []pathSegment) []pathSegment {
+	return append(slices.Clone(d.tm.path), segs...)
+}
+
+// ------------------- Path Copying -------------------
+
+// thaw returns v with shallow copies of the containers along segs, so that
+// setAt and deleteAt can write in place without touching the original.
+func thaw(v any, segs []pathSegment) any {
+	if len(segs) == 0 {
+		return v
+	}
+	switch c := v.(type) {
+	case map[string]any:
+		c = maps.Clone(c)
+		if child, ok := c[segs[0].key]; ok {
+			c[segs[0].key] = thaw(child, segs[1:])
+		}
+		return c
+	case []any:
+		c = slices.Clone(c)
+		if idx, err := sliceIndex(c, segs[0], false); err == nil {
+			c[idx] = thaw(c[idx], segs[1:])
+		}
+		return c
+	}
+	return v
+}
+
+func (d *ImmutableTreeMap) setSegments(segs []pathSegment, value any) TreeMapImpl {
+	return d.setNormalized(segs, normalizeToDefault(value))
+}
+
+// setNormalized is setSegments for a value that went through
+// normalizeToDefault already, so retries of a write do not copy it again.
+func (d *ImmutableTreeMap) setNormalized(segs []pathSegment, value any) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	segs = d.absolute(segs)
+	if len(segs) == 0 {
+		return d.commit(value)
+	}
+	root := d.rootTree().value
+	if !isContainer(root) {
+		return d.fail(mismatch("", "map or slice", root))
+	}
+	updated, err := setAt(thaw(root, segs), segs, value)
+	if err != nil {
+		return d.fail(err)
+	}
+	return d.commit(updated)
+}
+
+func (d *ImmutableTreeMap) deleteSegments(segs []pathSegment) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	segs = d.absolute(segs)
+	if len(segs) == 0 {
+		return d.fail(fmt.Errorf("cannot delete the root value"))
+	}
+	root := d.rootTree().value
+	if !isContainer(root) {
+		return d.fail(mismatch("", "map or slice", root))
+	}
+	updated, _, err := deleteAt(thaw(root, segs), segs)
+	if err != nil {
+		return d.fail(err)
+	}
+	return d.commit(updated)
+}
+
+// mutate runs write against a private deep copy of the tree, positioned at
+// d's path, and commits the result as a new version.
+func (d *ImmutableTreeMap) mutate(write func(node TreeMapImpl) TreeMapImpl) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	root := &TreeMap{value: deepClone(d.rootTree().value)}
+	root.root = root
+	node := root.getSegments(d.tm.path).(*TreeMap)
+	if res := write(node); res.Err() != nil {
+		return d.fail(res.Err())
+	}
+	return d.commit(root.value)
+}
+
+// ------------------- Core -------------------
+
+func (d *ImmutableTreeMap) Get(path string) TreeMapImpl {
+	return d.wrap(d.tm.Get(path))
+}
+
+func (d *ImmutableTreeMap) IsDefined(path string) bool {
+	return d.tm.IsDefined(path)
+}
+
+func (d *ImmutableTreeMap) Exists() bool {
+	return d.tm.Exists()
+}
+
+func (d *ImmutableTreeMap) Err() error {
+	return d.tm.Err()
+}
+
+func (d *ImmutableTreeMap) IsEmpty() bool {
+	return d.tm.IsEmpty()
+}
+
+func (d *ImmutableTreeMap) Or(path string) TreeMapImpl {
+	return d.wrap(d.tm.Or(path))
+}
+
+// Set returns a new version with value written at path.
+func (d *ImmutableTreeMap) Set(path string, value any) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	segs, err := parseConcretePath(path)
+	if err != nil {
+		return d.fail(err)
+	}
+	return d.setSegments(segs, value)
+}
+
+// Delete returns a new version without path. Unlike TreeMap.Delete it does
+// not return the removed value; read it with Get first.
+func (d *ImmutableTreeMap) Delete(path string) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	segs, err := parseConcretePath(path)
+	if err != nil {
+		return d.fail(err)
+	}
+	return d.deleteSegments(segs)
+}
+
+// TryDelete is Delete, returning the receiver when path cannot be deleted.
+func (d *ImmutableTreeMap) TryDelete(path string) TreeMapImpl {
+	if res := d.Delete(path); res.Err() == nil {
+		return res
+	}
+	return d
+}
+
+func (d *ImmutableTreeMap) Query(path string) ([]TreeMapImpl, error) {
+	matches, err := d.tm.Query(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range matches {
+		matches[i] = d.wrap(m)
+	}
+	return matches, nil
+}
+
+func (d *ImmutableTreeMap) SetAll(path string, value any) TreeMapImpl {
+	return d.mutate(func(node TreeMapImpl) TreeMapImpl { return node.SetAll(path, value) })
+}
+
+func (d *ImmutableTreeMap) DeleteAll(path string) TreeMapImpl {
+	return d.mutate(func(node TreeMapImpl) TreeMapImpl { return node.DeleteAll(path) })
+}
+
+func (d *ImmutableTreeMap) Path() string {
+	return d.tm.Path()
+}
+
+// ------------------- JSON Pointer -------------------
+
+func (d *ImmutableTreeMap) GetPointer(pointer string) TreeMapImpl {
+	return d.wrap(d.tm.GetPointer(pointer))
+}
+
+func (d *ImmutableTreeMap) SetPointer(pointer string, value any) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	segs, err := parsePointer(pointer)
+	if err != nil {
+		return d.fail(err)
+	}
+	return d.setSegments(segs, value)
+}
+
+func (d *ImmutableTreeMap) DeletePointer(pointer string) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	segs, err := parsePointer(pointer)
+	if err != nil {
+		return d.fail(err)
+	}
+	return d.deleteSegments(segs)
+}
+
+func (d *ImmutableTreeMap) Pointer() string {
+	return d.tm.Pointer()
+}
+
+func (d *ImmutableTreeMap) ApplyPatch(ops []PatchOperation) TreeMapImpl {
+	return d.mutate(func(node TreeMapImpl) TreeMapImpl { return node.ApplyPatch(ops) })
+}
+
+func (d *ImmutableTreeMap) Merge(other TreeMapImpl, opts ...MergeOptions) TreeMapImpl {
+	return d.mutate(func(node TreeMapImpl) TreeMapImpl { return node.Merge(other, opts...) })
+}
+
+func (d *ImmutableTreeMap) Validate(schema TreeMapImpl) error {
+	return d.tm.Validate(schema)
+}
+
+// ------------------- Iteration -------------------
+
+func (d *ImmutableTreeMap) All() iter.Seq2[string, TreeMapImpl] {
+	return func(yield func(string, TreeMapImpl) bool) {
+		for path, node := range d.tm.All() {
+			if !yield(path, d.wrap(node)) {
+				return
+			}
+		}
+	}
+}
+
+func (d *ImmutableTreeMap) Keys() iter.Seq[string] {
+	return d.tm.Keys()
+}
+
+func (d *ImmutableTreeMap) Entries() iter.Seq2[string, TreeMapImpl] {
+	return func(yield func(string, TreeMapImpl) bool) {
+		for k, node := range d.tm.Entries() {
+			if !yield(k, d.wrap(node)) {
+				return
+			}
+		}
+	}
+}
+
+func (d *ImmutableTreeMap) Len() int {
+	return d.tm.Len()
+}
+
+func (d *ImmutableTreeMap) Walk(fn WalkFunc) error {
+	return d.tm.Walk(func(path string, node TreeMapImpl) error {
+		return fn(path, d.wrap(node))
+	})
+}
+
+func (d *ImmutableTreeMap) Flatten(opts ...FlattenOptions) DefaultMap {
+	return d.tm.Flatten(opts...)
+}
+
+// Bind fills target from a private copy of this node, so `any` and map
+// fields never alias the version.
+func (d *ImmutableTreeMap) Bind(target any) error {
+	if d.tm.err != nil {
+		return d.tm.err
+	}
+	detached := &TreeMap{value: deepClone(d.tm.value), root: d.tm.root, path: d.tm.path}
+	return detached.Bind(target)
+}
+
+// WithConverter returns a new version that converts with c.
+func (d *ImmutableTreeMap) WithConverter(c *Converter) TreeMapImpl {
+	if d.tm.err != nil {
+		return d
+	}
+	root := &TreeMap{value: d.rootTree().value, conv: c}
+	root.root = root
+	return d.wrap(root.getSegments(d.tm.path))
+}
+
+func (d *ImmutableTreeMap) converter() *Converter {
+	return d.tm.converter()
+}
+
+// Clone returns the receiver: versions never change, so they can be shared.
+func (d *ImmutableTreeMap) Clone() TreeMapImpl {
+	return d
+}
+
+// ToJsonString returns "{}" when the value cannot be marshaled. Unlike
+// TreeMap it does not record the error, since versions are shared.
+func (d *ImmutableTreeMap) ToJsonString(pretty bool) string {
+	if d.tm.err != nil {
+		return "{}"
+	}
+	s, err := d.tm.marshalJson(pretty)
+	if err != nil {
+		return "{}"
+	}
+	return s
+}
+
+func (d *ImmutableTreeMap) AsMap() (DefaultMap, error) {
+	m, err := d.tm.AsMap()
+	if err != nil {
+		return nil, err
+	}
+	return deepClone(m).(DefaultMap), nil
+}
+
+func (d *ImmutableTreeMap) AsSlice() ([]TreeMapImpl, error) {
+	items, err := d.tm.AsSlice()
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i] = d.wrap(item)
+	}
+	return items, nil
+}
+
+// ------------------- Value Conversions -------------------
+
+func (d *ImmutableTreeMap) AsString() (string, error) {
+	return d.tm.AsString()
+}
+
+func (d *ImmutableTreeMap) AsInt() (int64, error) {
+	return d.tm.AsInt()
+}
+
+func (d *ImmutableTreeMap) AsFloat() (float64, error) {
+	return d.tm.AsFloat()
+}
+
+func (d *ImmutableTreeMap) AsBool() (bool, error) {
+	return d.tm.AsBool()
+}
+
+func (d *ImmutableTreeMap) AsAny() (any, error) {
+	v, err := d.tm.AsAny()
+	return deepClone(v), err
+}
+
+// ------------------- Struct / Slice Conversions -------------------
+
+func (d *ImmutableTreeMap) AsSliceOf(target []any) error {
+	return d.tm.AsSliceOf(target)
+}
+
+func (d *ImmutableTreeMap) AsStruct(target any) error {
+	return d.tm.AsStruct(target)
+}
+
+// ------------------- Default Fallbacks -------------------
+
+func (d *ImmutableTreeMap) AsStringOr(def string) string {
+	return d.tm.AsStringOr(def)
+}
+
+func (d *ImmutableTreeMap) AsIntOr(def int64) int64 {
+	return d.tm.AsIntOr(def)
+}
+
+func (d *ImmutableTreeMap) AsFloatOr(def float64) float64 {
+	return d.tm.AsFloatOr(def)
+}
+
+func (d *ImmutableTreeMap) AsBoolOr(def bool) bool {
+	return d.tm.AsBoolOr(def)
+}
+
+func (d *ImmutableTreeMap) AsAnyOr(def any) any {
+	return deepClone(d.tm.AsAnyOr(def))
+}
+
+// ------------------- Slices -------------------
+
+func (d *ImmutableTreeMap) AsStrSlice() []string {
+	return d.tm.AsStrSlice()
+}
+
+func (d *ImmutableTreeMap) AsIntSlice() []int64 {
+	return d.tm.AsIntSlice()
+}
+
+func (d *ImmutableTreeMap) AsBoolSlice() []bool {
+	return d.tm.AsBoolSlice()
+}
+
+func (d *ImmutableTreeMap) AsAnySlice() []any {
+	items := d.tm.AsAnySlice()
+	for i, v := range items {
+		items[i] = deepClone(v)
+	}
+	return items
+}
+
+// ------------------- Semantic Accessors -------------------
+
+func (d *ImmutableTreeMap) AsTime(layouts ...string) (time.Time, error) {
+	return d.tm.AsTime(layouts...)
+}
+
+func (d *ImmutableTreeMap) AsDuration() (time.Duration, error) {
+	return d.tm.AsDuration()
+}
+
+func (d *ImmutableTreeMap) AsURL() (*url.URL, error) {
+	return d.tm.AsURL()
+}
+
+func (d *ImmutableTreeMap) AsIP() (netip.Addr, error) {
+	return d.tm.AsIP()
+}
+
+func (d *ImmutableTreeMap) AsCIDR() (netip.Prefix, error) {
+	return d.tm.AsCIDR()
+}
+
+func (d *ImmutableTreeMap) AsUUID() (uuid.UUID, error) {
+	return d.tm.AsUUID()
+}
+
+func (d *ImmutableTreeMap) AsByteSize() (int64, error) {
+	return d.tm.AsByteSize()
+}
+
+func (d *ImmutableTreeMap) AsTimeOr(def time.Time, layouts ...string) time.Time {
+	return d.tm.AsTimeOr(def, layouts...)
+}
+
+func (d *ImmutableTreeMap) AsDurationOr(def time.Duration) time.Duration {
+	return d.tm.AsDurationOr(def)
+}
+
+func (d *ImmutableTreeMap) AsURLOr(def *url.URL) *url.URL {
+	return d.tm.AsURLOr(def)
+}
+
+func (d *ImmutableTreeMap) AsIPOr(def netip.Addr) netip.Addr {
+	return d.tm.AsIPOr(def)
+}
+
+func (d *ImmutableTreeMap) AsCIDROr(def netip.Prefix) netip.Prefix {
+	return d.tm.AsCIDROr(def)
+}
+
+func (d *ImmutableTreeMap) AsUUIDOr(def uuid.UUID) uuid.UUID {
+	return d.tm.AsUUIDOr(def)
+}
+
+func (d *ImmutableTreeMap) AsByteSizeOr(def int64) int64 {
+	return d.tm.AsByteSizeOr(def)
+}
+
+func (d *ImmutableTreeMap) AsTimeSlice(layouts ...string) []time.Time {
+	return d.tm.AsTimeSlice(layouts...)
+}
+
+func (d *ImmutableTreeMap) AsDurationSlice() []time.Duration {
+	return d.tm.AsDurationSlice()
+}
+
+func (d *ImmutableTreeMap) AsURLSlice() []*url.URL {
+	return d.tm.AsURLSlice()
+}
+
+func (d *ImmutableTreeMap) AsIPSlice() []netip.Addr {
+	return d.tm.AsIPSlice()
+}
+
+func (d *ImmutableTreeMap) AsCIDRSlice() []netip.Prefix {
+	return d.tm.AsCIDRSlice()
+}
+
+func (d *ImmutableTreeMap) AsUUIDSlice() []uuid.UUID {
+	return d.tm.AsUUIDSlice()
+}
+
+func (d *ImmutableTreeMap) AsByteSizeSlice() []int64 {
+	return d.tm.AsByteSizeSlice()
+}
+
+// ------------------- Internal -------------------
+
+func (d *ImmutableTreeMap) getValue() any {
+	return d.tm.getValue()
+}
+
+func (d *ImmutableTreeMap) getRoot() TreeMapImpl {
+	return d.wrap(d.tm.getRoot())
+}
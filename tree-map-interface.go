@@ -1,14 +1,43 @@
 package goutils
 
+import (
+	"iter"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type TreeMapImpl interface {
 	Get(path string) TreeMapImpl
 	IsDefined(path string) bool
 	Exists() bool
+	Err() error
 	IsEmpty() bool
 	Or(path string) TreeMapImpl
 	Set(path string, value any) TreeMapImpl
 	Delete(path string) TreeMapImpl
 	TryDelete(path string) TreeMapImpl
+	Query(path string) ([]TreeMapImpl, error)
+	SetAll(path string, value any) TreeMapImpl
+	DeleteAll(path string) TreeMapImpl
+	Path() string
+	GetPointer(pointer string) TreeMapImpl
+	SetPointer(pointer string, value any) TreeMapImpl
+	DeletePointer(pointer string) TreeMapImpl
+	Pointer() string
+	ApplyPatch(ops []PatchOperation) TreeMapImpl
+	Merge(other TreeMapImpl, opts ...MergeOptions) TreeMapImpl
+	Validate(schema TreeMapImpl) error
+	All() iter.Seq2[string, TreeMapImpl]
+	Keys() iter.Seq[string]
+	Entries() iter.Seq2[string, TreeMapImpl]
+	Len() int
+	Walk(fn WalkFunc) error
+	Flatten(opts ...FlattenOptions) DefaultMap
+	Bind(target any) error
+	WithConverter(c *Converter) TreeMapImpl
 	Clone() TreeMapImpl
 	ToJsonString(pretty bool) string
 	AsMap() (DefaultMap, error)
@@ -29,8 +58,29 @@ type TreeMapImpl interface {
 	AsIntSlice() []int64
 	AsBoolSlice() []bool
 	AsAnySlice() []any
+	AsTime(layouts ...string) (time.Time, error)
+	AsDuration() (time.Duration, error)
+	AsURL() (*url.URL, error)
+	AsIP() (netip.Addr, error)
+	AsCIDR() (netip.Prefix, error)
+	AsUUID() (uuid.UUID, error)
+	AsByteSize() (int64, error)
+	AsTimeOr(def time.Time, layouts ...string) time.Time
+	AsDurationOr(def time.Duration) time.Duration
+	AsURLOr(def *url.URL) *url.URL
+	AsIPOr(def netip.Addr) netip.Addr
+	AsCIDROr(def netip.Prefix) netip.Prefix
+	AsUUIDOr(def uuid.UUID) uuid.UUID
+	AsByteSizeOr(def int64) int64
+	AsTimeSlice(layouts ...string) []time.Time
+	AsDurationSlice() []time.Duration
+	AsURLSlice() []*url.URL
+	AsIPSlice() []netip.Addr
+	AsCIDRSlice() []netip.Prefix
+	AsUUIDSlice() []uuid.UUID
+	AsByteSizeSlice() []int64
 
 	getValue() any
-	getError() error
 	getRoot() TreeMapImpl
+	converter() *Converter
 }
@@ -0,0 +1,122 @@
+package goutils
+
+import (
+	"errors"
+	"iter"
+	"strconv"
+)
+
+// ------------------- Iteration -------------------
+
+var (
+	// SkipSubtree returned from a WalkFunc skips the children of the node.
+	SkipSubtree = errors.New("skip subtree")
+	// SkipAll returned from a WalkFunc stops the walk, Walk returns nil.
+	SkipAll = errors.New("skip all")
+)
+
+// WalkFunc receives each node with its path relative to the walked tree.
+type WalkFunc func(path string, node TreeMapImpl) error
+
+// Walk visits every descendant depth first (map keys sorted, slices in
+// order), parents before children.
+func (d *TreeMap) Walk(fn WalkFunc) error {
+	if d.err != nil {
+		return d.err
+	}
+	err := d.walk(nil, d.value, fn)
+	if errors.Is(err, SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func (d *TreeMap) walk(rel []pathSegment, value any, fn WalkFunc) error {
+	for _, c := range childrenOf(value) {
+		childRel := append(rel[:len(rel):len(rel)], c.seg)
+		err := fn(formatPath(childRel), d.child(childRel, c.value))
+		if errors.Is(err, SkipSubtree) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := d.walk(childRel, c.value, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// child builds a node for a descendant at rel (relative to d).
+func (d *TreeMap) child(rel []pathSegment, value any) *TreeMap {
+	path := make([]pathSegment, 0, len(d.path)+len(rel))
+	path = append(append(path, d.path...), rel...)
+	return &TreeMap{value: value, root: d.root, path: path}
+}
+
+// All yields every leaf with its relative path. Scalars and empty maps or
+// slices are leaves; a scalar receiver yields itself with path "".
+func (d *TreeMap) All() iter.Seq2[string, TreeMapImpl] {
+	return func(yield func(string, TreeMapImpl) bool) {
+		if d.err != nil {
+			return
+		}
+		if !isContainer(d.value) {
+			yield("", d)
+			return
+		}
+		_ = d.walk(nil, d.value, func(path string, node TreeMapImpl) error {
+			if node.Len() > 0 {
+				return nil
+			}
+			if !yield(path, node) {
+				return SkipAll
+			}
+			return nil
+		})
+	}
+}
+
+// Keys yields map keys in sorted order, or slice indexes.
+func (d *TreeMap) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for k := range d.Entries() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Entries yields the direct children keyed by map key or slice index.
+func (d *TreeMap) Entries() iter.Seq2[string, TreeMapImpl] {
+	return func(yield func(string, TreeMapImpl) bool) {
+		if d.err != nil {
+			return
+		}
+		for _, c := range childrenOf(d.value) {
+			key := c.seg.key
+			if c.seg.kind == segmentIndex {
+				key = strconv.Itoa(c.seg.index)
+			}
+			if !yield(key, d.child([]pathSegment{c.seg}, c.value)) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of direct children, 0 for scalars.
+func (d *TreeMap) Len() int {
+	if d.err != nil {
+		return 0
+	}
+	switch v := d.value.(type) {
+	case map[string]any:
+		return len(v)
+	case []any:
+		return len(v)
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package goutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ------------------- JSON Decoding -------------------
+
+type NumberMode int
+
+const (
+	// NumberInteger decodes integral numbers as int64, or uint64 above
+	// MaxInt64. Larger integers stay json.Number, the rest are float64.
+	NumberInteger NumberMode = iota
+	// NumberJSON keeps every number as json.Number.
+	NumberJSON
+	// NumberFloat decodes numbers as float64 like encoding/json does.
+	NumberFloat
+)
+
+type JSONOptions struct {
+	Numbers NumberMode
+}
+
+// NewTreeMapFromJSON decodes a single JSON value from r. Unlike
+// json.Unmarshal into any, 64-bit ids survive by default (see NumberMode).
+func NewTreeMapFromJSON(r io.Reader, opts ...JSONOptions) TreeMapImpl {
+	var o JSONOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return &TreeMap{err: err}
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return &TreeMap{err: fmt.Errorf("unexpected data after JSON value")}
+	}
+
+	root := &TreeMap{value: decodeNumbers(data, o.Numbers)}
+	root.root = root
+	return root
+}
+
+// ParseJSON is NewTreeMapFromJSON for a byte slice.
+func ParseJSON(data []byte, opts ...JSONOptions) TreeMapImpl {
+	return NewTreeMapFromJSON(bytes.NewReader(data), opts...)
+}
+
+func decodeNumbers(v any, mode NumberMode) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			t[k] = decodeNumbers(e, mode)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = decodeNumbers(e, mode)
+		}
+	case json.Number:
+		switch mode {
+		case NumberJSON:
+			return t
+		case NumberFloat:
+			f, _ := t.Float64()
+			return f
+		}
+		return unwrapNumber(t)
+	}
+	return v
+}
+
+// unwrapNumber turns a json.Number into int64, uint64 or float64, keeping
+// it as json.Number when it is an integer too large for 64 bits.
+func unwrapNumber(v any) any {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u
+	}
+	f, err := n.Float64()
+	if err != nil || isIntegerLiteral(string(n)) {
+		return n
+	}
+	return f
+}
+
+func isIntegerLiteral(s string) bool {
+	if len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
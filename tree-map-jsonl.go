@@ -0,0 +1,186 @@
+package goutils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// ------------------- JSON Lines -------------------
+
+// ErrLineTooLong is wrapped by a LineError for lines above MaxLineSize.
+var ErrLineTooLong = errors.New("line too long")
+
+const defaultMaxLineSize = 1 << 20
+
+type JSONLinesOptions struct {
+	MaxLineSize   int  // in bytes, defaults to 1 MiB
+	Gzip          bool // the input (or, for the writer, the output) is gzip compressed
+	SkipMalformed bool // skip bad or oversized lines instead of stopping
+	// OnError is called with a *LineError for every skipped line.
+	OnError func(err error)
+	JSON    JSONOptions // number decoding, see NewTreeMapFromJSON
+}
+
+// LineError reports a line (1-based) that could not be read or decoded.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ReadJSONLines yields one tree per non-blank line of r, reading a line at a
+// time so memory stays bounded by MaxLineSize. On an error the iterator
+// yields an error tree with the error and stops, unless SkipMalformed is set
+// and the error is about a single line.
+//
+//	for event, err := range goutils.ReadJSONLines(file) {
+//		if err != nil {
+//			return err
+//		}
+//	}
+func ReadJSONLines(r io.Reader, opts ...JSONLinesOptions) iter.Seq2[TreeMapImpl, error] {
+	var o JSONLinesOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.MaxLineSize <= 0 {
+		o.MaxLineSize = defaultMaxLineSize
+	}
+
+	return func(yield func(TreeMapImpl, error) bool) {
+		src := r
+		if o.Gzip {
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				yield(&TreeMap{err: err}, err)
+				return
+			}
+			defer gz.Close()
+			src = gz
+		}
+
+		br := bufio.NewReader(src)
+		for line := 1; ; line++ {
+			data, tooLong, err := readLine(br, o.MaxLineSize)
+			if err != nil && !errors.Is(err, io.EOF) {
+				yield(&TreeMap{err: err}, err)
+				return
+			}
+			eof := err != nil
+
+			var lineErr error
+			var tree TreeMapImpl
+			switch {
+			case tooLong:
+				lineErr = &LineError{Line: line, Err: ErrLineTooLong}
+			case len(bytes.TrimSpace(data)) > 0:
+				tree = ParseJSON(data, o.JSON)
+				if err := tree.Err(); err != nil {
+					lineErr = &LineError{Line: line, Err: err}
+				}
+			}
+
+			switch {
+			case lineErr != nil && o.SkipMalformed:
+				if o.OnError != nil {
+					o.OnError(lineErr)
+				}
+			case lineErr != nil:
+				yield(&TreeMap{err: lineErr}, lineErr)
+				return
+			case tree != nil:
+				if !yield(tree, nil) {
+					return
+				}
+			}
+			if eof {
+				return
+			}
+		}
+	}
+}
+
+// readLine reads up to the next '\n'. Past max bytes the rest of the line is
+// discarded and tooLong is set. err is io.EOF on the last line.
+func readLine(br *bufio.Reader, max int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > max+1 {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		return line, tooLong, err
+	}
+}
+
+// ------------------- Writer -------------------
+
+// JSONLinesWriter writes one compact JSON document per line.
+type JSONLinesWriter struct {
+	buf *bufio.Writer
+	gz  *gzip.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLinesWriter buffers output to w; only Gzip is used from opts.
+// Call Close (or Flush) when done.
+func NewJSONLinesWriter(w io.Writer, opts ...JSONLinesOptions) *JSONLinesWriter {
+	out := &JSONLinesWriter{}
+	if len(opts) > 0 && opts[0].Gzip {
+		out.gz = gzip.NewWriter(w)
+		w = out.gz
+	}
+	out.buf = bufio.NewWriter(w)
+	out.enc = json.NewEncoder(out.buf)
+	return out
+}
+
+// Write appends tree as a line, or returns the tree's error.
+func (w *JSONLinesWriter) Write(tree TreeMapImpl) error {
+	if err := tree.Err(); err != nil {
+		return err
+	}
+	return w.enc.Encode(tree.getValue())
+}
+
+// Flush writes buffered lines to the underlying writer.
+func (w *JSONLinesWriter) Flush() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		return w.gz.Flush()
+	}
+	return nil
+}
+
+// Close flushes and ends the gzip stream. It does not close the
+// underlying writer.
+func (w *JSONLinesWriter) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package goutils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ------------------- Merge -------------------
+
+type ArrayMergeStrategy int
+
+const (
+	// ArrayReplace treats slices like scalars: the conflict strategy decides.
+	ArrayReplace ArrayMergeStrategy = iota
+	// ArrayAppend appends the right elements to the left slice.
+	ArrayAppend
+	// ArrayMergeByKey merges map elements sharing MergeOptions.ArrayKey and
+	// appends the rest.
+	ArrayMergeByKey
+)
+
+type NullMergeStrategy int
+
+const (
+	// NullDelete removes the key, as in RFC 7396.
+	NullDelete NullMergeStrategy = iota
+	// NullSet stores the null value.
+	NullSet
+	// NullSkip ignores nulls and keeps the left value.
+	NullSkip
+)
+
+type ConflictStrategy int
+
+const (
+	ConflictRightWins ConflictStrategy = iota
+	ConflictLeftWins
+	ConflictError
+)
+
+// MergeOptions configures Merge. The zero value is an RFC 7396 JSON Merge
+// Patch: maps merge recursively, slices and scalars are replaced and nulls
+// delete keys.
+type MergeOptions struct {
+	Arrays    ArrayMergeStrategy
+	ArrayKey  string // used by ArrayMergeByKey, defaults to "id"
+	Nulls     NullMergeStrategy
+	Conflicts ConflictStrategy
+}
+
+// Merge deep merges other into the tree in place and returns the tree.
+// With ConflictError nothing is modified when a conflict is found.
+func (d *TreeMap) Merge(other TreeMapImpl, opts ...MergeOptions) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	if err := other.Err(); err != nil {
+		return &TreeMap{err: err}
+	}
+
+	m := &merger{}
+	if len(opts) > 0 {
+		m.opts = opts[0]
+	}
+	if m.opts.ArrayKey == "" {
+		m.opts.ArrayKey = "id"
+	}
+
+	src := other.getValue()
+	if m.opts.Conflicts == ConflictError {
+		if _, err := m.merge(deepClone(d.value), src, nil); err != nil {
+			return &TreeMap{err: err}
+		}
+	}
+	old := d.value
+	merged, err := m.merge(d.value, src, nil)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	d.value = merged
+	d.attach(old)
+	return d
+}
+
+type merger struct {
+	opts MergeOptions
+}
+
+func (m *merger) merge(dst, src any, segs []pathSegment) (any, error) {
+	dstMap, dstIsMap := dst.(map[string]any)
+	srcMap, srcIsMap := src.(map[string]any)
+	if dstIsMap && srcIsMap {
+		return m.mergeMaps(dstMap, srcMap, segs)
+	}
+
+	dstList, dstIsList := dst.([]any)
+	srcList, srcIsList := src.([]any)
+	if dstIsList && srcIsList {
+		switch m.opts.Arrays {
+		case ArrayAppend:
+			return append(dstList, deepClone(srcList).([]any)...), nil
+		case ArrayMergeByKey:
+			return m.mergeByKey(dstList, srcList, segs)
+		}
+	}
+
+	if valuesEqual(dst, src, true) {
+		return dst, nil
+	}
+	switch m.opts.Conflicts {
+	case ConflictLeftWins:
+		return dst, nil
+	case ConflictError:
+		return nil, fmt.Errorf("merge conflict at %s", formatPath(segs))
+	default:
+		return m.fresh(src)
+	}
+}
+
+func (m *merger) mergeMaps(dst, src map[string]any, segs []pathSegment) (any, error) {
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sv := src[k]
+		if sv == nil {
+			switch m.opts.Nulls {
+			case NullDelete:
+				delete(dst, k)
+			case NullSet:
+				dst[k] = nil
+			}
+			continue
+		}
+
+		dv, exists := dst[k]
+		if !exists {
+			v, err := m.fresh(sv)
+			if err != nil {
+				return nil, err
+			}
+			dst[k] = v
+			continue
+		}
+		merged, err := m.merge(dv, sv, append(segs[:len(segs):len(segs)], keySegment(k)))
+		if err != nil {
+			return nil, err
+		}
+		dst[k] = merged
+	}
+	return dst, nil
+}
+
+func (m *merger) mergeByKey(dst, src []any, segs []pathSegment) (any, error) {
+	byKey := make(map[any]int, len(dst))
+	for i, e := range dst {
+		if id, ok := m.elementKey(e); ok {
+			byKey[id] = i
+		}
+	}
+
+	for _, e := range src {
+		if id, ok := m.elementKey(e); ok {
+			if i, found := byKey[id]; found {
+				merged, err := m.merge(dst[i], e, append(segs[:len(segs):len(segs)], indexSegment(i)))
+				if err != nil {
+					return nil, err
+				}
+				dst[i] = merged
+				continue
+			}
+		}
+		v, err := m.fresh(e)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, v)
+	}
+	return dst, nil
+}
+
+// fresh copies a right-hand value into the tree. Maps are merged into an
+// empty map so nested nulls follow the null strategy.
+func (m *merger) fresh(src any) (any, error) {
+	if sm, ok := src.(map[string]any); ok {
+		return m.mergeMaps(make(map[string]any, len(sm)), sm, nil)
+	}
+	return deepClone(src), nil
+}
+
+// elementKey returns the ArrayKey value of a map element. Numbers are keyed
+// as float64 so an int id matches a JSON-decoded one.
+func (m *merger) elementKey(e any) (any, bool) {
+	em, ok := e.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	switch id := em[m.opts.ArrayKey].(type) {
+	case string, bool:
+		return id, true
+	default:
+		if isNumber(id) {
+			return toFloat64(reflect.ValueOf(id)), true
+		}
+	}
+	return nil, false
+}
@@ -1,10 +1,15 @@
 package goutils
 
 import (
+	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
-	"strings"
+	"time"
 )
 
 // ------------------- Get / Set / Delete -------------------
@@ -14,92 +19,227 @@ func (d *TreeMap) Get(path string) TreeMapImpl {
 	if d.err != nil || d.value == nil {
 		return d
 	}
-	parts := strings.Split(path, ".")
+	segs, err := parseConcretePath(path)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	return d.getSegments(segs)
+}
+
+func (d *TreeMap) getSegments(segs []pathSegment) TreeMapImpl {
+	if d.err != nil || len(segs) == 0 {
+		return d
+	}
+	var err error
 	current := d.value
+	resolved := make([]pathSegment, len(d.path), len(d.path)+len(segs))
+	copy(resolved, d.path)
+
+	for _, seg := range segs {
+		var next any
+		next, seg, err = stepInto(current, seg)
+		if err != nil {
+			return &TreeMap{err: pathError(err, append(resolved, seg), current), root: d.root}
+		}
+		current = next
+		resolved = append(resolved, seg)
+	}
+	return &TreeMap{value: current, root: d.root, path: resolved}
+}
+
+// stepInto resolves a single segment against a container value. The returned
+// segment is the concrete one that was followed (an index for slices).
+// Errors are ErrNotFound, ErrIndexOutOfRange or errNotContainer.
+func stepInto(current any, seg pathSegment) (any, pathSegment, error) {
+	part := seg.key
+	switch v := current.(type) {
+	case map[string]any:
+		val, ok := v[part]
+		if !ok {
+			return nil, keySegment(part), ErrNotFound
+		}
+		return val, keySegment(part), nil
+
+	case []any:
+		idx, ok := parseIndex(part)
+		if !ok {
+			return nil, seg, errNotContainer
+		}
+		if idx < 0 || idx >= len(v) {
+			return nil, seg, ErrIndexOutOfRange
+		}
+		return v[idx], indexSegment(idx), nil
 
-	for _, part := range parts {
-		switch v := current.(type) {
-		case map[string]any:
-			current = v[part]
+	case nil:
+		return nil, seg, ErrNotFound
 
-		case []any:
-			idx, err := strconv.Atoi(part)
-			if err != nil || idx < 0 || idx >= len(v) {
-				return &TreeMap{err: fmt.Errorf("index out of bounds: %s", part)}
+	default:
+		// Fallback: si todavía no está normalizado, probamos una vez
+		rv := reflect.ValueOf(current)
+		switch rv.Kind() {
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return nil, seg, errNotContainer
 			}
-			current = v[idx]
+			val := rv.MapIndex(reflect.ValueOf(part).Convert(rv.Type().Key()))
+			if !val.IsValid() {
+				return nil, seg, ErrNotFound
+			}
+			return val.Interface(), keySegment(part), nil
 
-		default:
-			// Fallback: si todavía no está normalizado, probamos una vez
-			rv := reflect.ValueOf(current)
-			switch rv.Kind() {
-			case reflect.Map:
-				if rv.Type().Key().Kind() != reflect.String {
-					return &TreeMap{err: fmt.Errorf("map key is not string at %s", part)}
-				}
-				val := rv.MapIndex(reflect.ValueOf(part))
-				if !val.IsValid() {
-					return &TreeMap{err: fmt.Errorf("key not found: %s", part)}
-				}
-				current = val.Interface()
-
-			case reflect.Slice, reflect.Array:
-				i, err := strconv.Atoi(part)
-				if err != nil || i < 0 || i >= rv.Len() {
-					return &TreeMap{err: fmt.Errorf("index out of bounds: %s", part)}
-				}
-				current = rv.Index(i).Interface()
-
-			default:
-				return &TreeMap{err: fmt.Errorf("invalid access at %s", part)}
+		case reflect.Slice, reflect.Array:
+			i, ok := parseIndex(part)
+			if !ok {
+				return nil, seg, errNotContainer
+			}
+			if i < 0 || i >= rv.Len() {
+				return nil, seg, ErrIndexOutOfRange
 			}
+			return rv.Index(i).Interface(), indexSegment(i), nil
+
+		default:
+			return nil, seg, errNotContainer
 		}
 	}
-	return &TreeMap{value: current, root: d.root}
+}
+
+// errNotContainer is turned into an *ErrTypeMismatch by pathError.
+var errNotContainer = errors.New("not a map or slice")
+
+// pathError attaches the full path of the failing segment to a stepInto or
+// sliceIndex error; parent is the value the segment was applied to.
+func pathError(err error, segs []pathSegment, parent any) error {
+	path := formatPath(segs)
+	if errors.Is(err, errNotContainer) {
+		want := "map or slice"
+		if _, ok := parent.([]any); ok {
+			want = "map"
+		}
+		return mismatch(path, want, parent)
+	}
+	return &PathError{Path: path, Err: err}
+}
+
+func keySegment(key string) pathSegment {
+	return pathSegment{kind: segmentKey, key: key, quoted: true}
+}
+
+func indexSegment(idx int) pathSegment {
+	return pathSegment{kind: segmentIndex, key: strconv.Itoa(idx), index: idx}
 }
 
 // ------------------- Set -------------------
+
+// Set writes value at path. Slices are indexed by number and `-` appends;
+// an index past the end pads the slice with nulls, by at most
+// MaxSlicePadding slots. Missing containers are created as slices when the
+// next segment is an index (or `-`) and as maps otherwise.
 func (d *TreeMap) Set(path string, value any) TreeMapImpl {
 	if d.err != nil {
 		return d
 	}
+	segs, err := parseConcretePath(path)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	return d.setSegments(segs, value)
+}
 
-	root, ok := d.value.(map[string]any)
-	if !ok {
-		return &TreeMap{err: fmt.Errorf("root is not a map")}
+// setSegments writes value at segs; an empty segs replaces the whole value.
+func (d *TreeMap) setSegments(segs []pathSegment, value any) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	old := d.value
+	if len(segs) == 0 {
+		d.value = normalizeToDefault(value)
+		d.attach(old)
+		return d
 	}
+	if !isContainer(d.value) {
+		return &TreeMap{err: mismatch(d.Path(), "map or slice", d.value)}
+	}
+	updated, err := setAt(d.value, segs, normalizeToDefault(value))
+	if err != nil {
+		return &TreeMap{err: prefixErrorPath(err, d.path)}
+	}
+	d.value = updated
+	d.attach(old)
+	return d
+}
+
+// attach stores d.value back into d's root after a write replaced the
+// container d points at, e.g. a slice that grew or was spliced, or a null
+// replaced by SetPointer(""). Views whose slot in the root no longer holds the old
+// value, such as clones or views of deleted paths, are left alone.
+func (d *TreeMap) attach(old any) {
+	root, ok := d.root.(*TreeMap)
+	if !ok || root == d || len(d.path) == 0 || sameContainer(old, d.value) {
+		return
+	}
+	cur, ok := lookupValue(root.value, d.path)
+	if !ok || (old == nil && cur != nil) || (old != nil && !sameContainer(cur, old)) {
+		return
+	}
+	if updated, err := setAt(root.value, d.path, d.value); err == nil {
+		root.value = updated
+	}
+}
 
-	parts := strings.Split(path, ".")
-	last := len(parts) - 1
-	curr := root
+// setAt returns current with value written at segs. Slices may grow, so
+// callers must store the returned container back into its parent. Errors
+// carry the path relative to current.
+func setAt(current any, segs []pathSegment, value any) (any, error) {
+	return setAtFrom(current, segs, 0, value)
+}
+
+func setAtFrom(current any, segs []pathSegment, i int, value any) (any, error) {
+	seg, last := segs[i], i == len(segs)-1
 
-	for i, part := range parts {
-		if i == last {
-			curr[part] = normalizeToDefault(value)
-			return d
+	switch c := current.(type) {
+	case map[string]any:
+		if last {
+			c[seg.key] = value
+			return c, nil
 		}
-		next, ok := curr[part]
-		if !ok || next == nil {
-			nm := make(map[string]any)
-			curr[part] = nm
-			curr = nm
-			continue
+		child, err := setAtFrom(c[seg.key], segs, i+1, value)
+		if err != nil {
+			return nil, err
 		}
+		c[seg.key] = child
+		return c, nil
 
-		if mm, ok := next.(map[string]any); ok {
-			curr = mm
-			continue
+	case []any:
+		idx, err := sliceIndex(c, seg, true)
+		if err != nil {
+			return nil, pathError(err, segs[:i+1], c)
+		}
+		if idx >= len(c) {
+			c = append(c, make([]any, idx-len(c)+1)...)
 		}
+		if last {
+			c[idx] = value
+			return c, nil
+		}
+		child, err := setAtFrom(c[idx], segs, i+1, value)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = child
+		return c, nil
 
-		nn := normalizeToDefault(next)
-		mm, ok := nn.(map[string]any)
-		if !ok {
-			return &TreeMap{err: fmt.Errorf("intermediate value at %s is not a map", part)}
+	case nil:
+		if seg.isIndexLike() {
+			return setAtFrom([]any{}, segs, i, value)
 		}
-		curr[part] = mm
-		curr = mm
+		return setAtFrom(make(map[string]any), segs, i, value)
+
+	default:
+		if nn := normalizeToDefault(current); isContainer(nn) {
+			return setAtFrom(nn, segs, i, value)
+		}
+		return nil, mismatch(formatPath(segs[:i]), "map or slice", current)
 	}
-	return d
 }
 
 func (d *TreeMap) Or(path string) TreeMapImpl {
@@ -117,33 +257,127 @@ func (d *TreeMap) Delete(path string) TreeMapImpl {
 	if d.err != nil {
 		return d
 	}
+	segs, err := parseConcretePath(path)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	return d.deleteSegments(segs)
+}
 
-	root, ok := d.value.(DefaultMap)
-	if !ok {
-		return &TreeMap{err: fmt.Errorf("root is not a map")}
+func (d *TreeMap) deleteSegments(segs []pathSegment) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	if len(segs) == 0 {
+		return &TreeMap{err: fmt.Errorf("cannot delete the root value")}
 	}
+	if !isContainer(d.value) {
+		return &TreeMap{err: mismatch(d.Path(), "map or slice", d.value)}
+	}
+	old := d.value
+	updated, removed, err := deleteAt(d.value, segs)
+	if err != nil {
+		return &TreeMap{err: prefixErrorPath(err, d.path)}
+	}
+	d.value = updated
+	d.attach(old)
+	return &TreeMap{value: removed}
+}
 
-	parts := strings.Split(path, ".")
-	last := len(parts) - 1
-	current := root
+// deleteAt removes the value at segs, splicing slices, and returns the
+// updated container together with the removed value. Errors carry the path
+// relative to current.
+func deleteAt(current any, segs []pathSegment) (any, any, error) {
+	return deleteAtFrom(current, segs, 0)
+}
+
+func deleteAtFrom(current any, segs []pathSegment, i int) (any, any, error) {
+	seg, last := segs[i], i == len(segs)-1
 
-	for i, part := range parts {
-		if i == last {
-			val := current[part]
-			delete(current, part)
-			return &TreeMap{value: val}
+	switch c := current.(type) {
+	case map[string]any:
+		if last {
+			val := c[seg.key]
+			delete(c, seg.key)
+			return c, val, nil
 		}
-		next, ok := current[part]
+		next, ok := c[seg.key]
 		if !ok {
-			return &TreeMap{err: fmt.Errorf("not found key '%s'", part)}
+			return nil, nil, pathError(ErrNotFound, segs[:i+1], c)
 		}
-		nestedMap, ok := next.(DefaultMap)
-		if !ok {
-			return &TreeMap{err: fmt.Errorf("intermediate path '%s' is not a map", part)}
+		child, removed, err := deleteAtFrom(next, segs, i+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		c[seg.key] = child
+		return c, removed, nil
+
+	case []any:
+		idx, err := sliceIndex(c, seg, false)
+		if err != nil {
+			return nil, nil, pathError(err, segs[:i+1], c)
+		}
+		if last {
+			val := c[idx]
+			return slices.Delete(c, idx, idx+1), val, nil
+		}
+		child, removed, err := deleteAtFrom(c[idx], segs, i+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		c[idx] = child
+		return c, removed, nil
+
+	default:
+		return nil, nil, mismatch(formatPath(segs[:i]), "map or slice", current)
+	}
+}
+
+// MaxSlicePadding is the number of null slots a single write may add in
+// front of the element it sets past the end of a slice. Larger gaps fail
+// with ErrIndexOutOfRange, so an untrusted path cannot allocate a huge slice.
+const MaxSlicePadding = 1024
+
+// sliceIndex resolves seg against s. When grow is true the index may point
+// past the end (padding, up to MaxSlicePadding) and `-` resolves to len(s).
+func sliceIndex(s []any, seg pathSegment, grow bool) (int, error) {
+	if seg.key == "-" {
+		if grow {
+			return len(s), nil
+		}
+		return 0, ErrIndexOutOfRange
+	}
+	idx, ok := parseIndex(seg.key)
+	if !ok {
+		return 0, errNotContainer
+	}
+	if (!grow && idx >= len(s)) || idx-len(s) > MaxSlicePadding {
+		return 0, ErrIndexOutOfRange
+	}
+	return idx, nil
+}
+
+// parseIndex reads an array index spelled the RFC 6901 way: `0`, or digits
+// without a sign or leading zeros.
+func parseIndex(tok string) (int, bool) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return 0, false
 		}
-		current = nestedMap
 	}
-	return &TreeMap{err: fmt.Errorf("cannot delete path '%s'", path)}
+	n, err := strconv.Atoi(tok)
+	return n, err == nil
+}
+
+func isContainer(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
 }
 
 // delete path key and returns the root treemap
@@ -160,22 +394,49 @@ func (d *TreeMap) TryDelete(path string) TreeMapImpl {
 // ------------------- Status -------------------
 func (d *TreeMap) IsDefined(path string) bool {
 	v := d.Get(path)
-	return v.getError() == nil && v.getValue() != nil
+	return v.Err() == nil && v.getValue() != nil
 }
 
 func (d *TreeMap) Exists() bool {
 	return d.err == nil
 }
 
+// IsEmpty reports a null or missing value.
 func (d *TreeMap) IsEmpty() bool {
-	return d.err == nil && d.value == nil
+	return d.value == nil && (d.err == nil || isMissing(d.err))
 }
 
+// normalizeToDefault converts maps, slices, arrays and structs (see
+// FromStruct) into map[string]any and []any trees. Like encoding/json,
+// json.Marshaler and encoding.TextMarshaler values are stored in their
+// marshalled form, so netip.Addr becomes a string; time.Time and url.URL
+// are stored as text as well.
 func normalizeToDefault(v any) any {
 	if v == nil {
 		return nil
 	}
 	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		if rv.Type().Elem().Kind() == reflect.Struct {
+			return nil
+		}
+		return v
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case json.Marshaler:
+		return normalizeMarshaler(t)
+	case encoding.TextMarshaler:
+		if text, err := t.MarshalText(); err == nil {
+			return string(text)
+		}
+	case *url.URL:
+		return t.String()
+	case url.URL:
+		return t.String()
+	}
+
 	switch rv.Kind() {
 	case reflect.Map:
 		if rv.Type().Key().Kind() != reflect.String {
@@ -193,6 +454,15 @@ func normalizeToDefault(v any) any {
 			out[i] = normalizeToDefault(rv.Index(i).Interface())
 		}
 		return out
+	case reflect.Pointer:
+		if rv.Elem().Kind() == reflect.Struct {
+			return normalizeToDefault(rv.Elem().Interface())
+		}
+		return v
+	case reflect.Struct:
+		out := make(map[string]any)
+		normalizeStruct(rv, out)
+		return out
 	default:
 		return v
 	}
@@ -0,0 +1,339 @@
+package goutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ------------------- JSON Patch (RFC 6902) -------------------
+
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON always writes "value" for add/replace/test, even when it is null.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	out := DefaultMap{"op": op.Op, "path": op.Path}
+	switch op.Op {
+	case "add", "replace", "test":
+		out["value"] = op.Value
+	case "move", "copy":
+		out["from"] = op.From
+	}
+	return json.Marshal(out)
+}
+
+// ParsePatch decodes a JSON Patch document.
+func ParsePatch(data []byte) ([]PatchOperation, error) {
+	var raw []struct {
+		Op    string           `json:"op"`
+		Path  *string          `json:"path"`
+		From  *string          `json:"from"`
+		Value *json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	ops := make([]PatchOperation, len(raw))
+	for i, r := range raw {
+		if r.Path == nil {
+			return nil, fmt.Errorf("patch op %d: missing path", i)
+		}
+		op := PatchOperation{Op: r.Op, Path: *r.Path}
+		switch r.Op {
+		case "add", "replace", "test":
+			if r.Value == nil {
+				return nil, fmt.Errorf("patch op %d: %s requires value", i, r.Op)
+			}
+			if err := json.Unmarshal(*r.Value, &op.Value); err != nil {
+				return nil, err
+			}
+		case "move", "copy":
+			if r.From == nil {
+				return nil, fmt.Errorf("patch op %d: %s requires from", i, r.Op)
+			}
+			op.From = *r.From
+		case "remove":
+		default:
+			return nil, fmt.Errorf("patch op %d: unknown op %q", i, r.Op)
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
+// ApplyPatch applies every operation or none of them. The patch is first run
+// against a deep copy; only when all operations succeed is it replayed on
+// the live tree, so existing child views stay attached.
+func (d *TreeMap) ApplyPatch(ops []PatchOperation) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	if _, err := applyPatch(deepClone(d.value), ops); err != nil {
+		return &TreeMap{err: err}
+	}
+	old := d.value
+	updated, _ := applyPatch(d.value, ops)
+	d.value = updated
+	d.attach(old)
+	return d
+}
+
+func applyPatch(doc any, ops []PatchOperation) (any, error) {
+	for i, op := range ops {
+		var err error
+		doc, err = applyPatchOp(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("patch op %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyPatchOp(doc any, op PatchOperation) (any, error) {
+	segs, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return patchAdd(doc, segs, normalizeToDefault(op.Value))
+
+	case "remove":
+		if len(segs) == 0 {
+			return nil, nil
+		}
+		if _, ok := lookupSegments(doc, segs); !ok {
+			return nil, fmt.Errorf("path not found")
+		}
+		updated, _, err := deleteAt(doc, segs)
+		return updated, err
+
+	case "replace":
+		if _, ok := lookupSegments(doc, segs); !ok {
+			return nil, fmt.Errorf("path not found")
+		}
+		if len(segs) == 0 {
+			return normalizeToDefault(op.Value), nil
+		}
+		return setAt(doc, segs, normalizeToDefault(op.Value))
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		val, ok := lookupSegments(doc, from)
+		if !ok {
+			return nil, fmt.Errorf("from %q not found", op.From)
+		}
+		if op.Op == "copy" {
+			return patchAdd(doc, segs, deepClone(val))
+		}
+		if len(from) < len(segs) && formatPointer(segs[:len(from)]) == formatPointer(from) {
+			return nil, fmt.Errorf("cannot move %q into its own child", op.From)
+		}
+		if len(from) == 0 {
+			return nil, fmt.Errorf("cannot move the root value")
+		}
+		doc, _, err = deleteAt(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, segs, val)
+
+	case "test":
+		val, ok := lookupSegments(doc, segs)
+		if !ok {
+			return nil, fmt.Errorf("path not found")
+		}
+		if !valuesEqual(val, normalizeToDefault(op.Value), true) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// patchAdd implements the RFC 6902 "add": the parent must exist, map keys
+// are set and slice indexes insert (shifting the rest).
+func patchAdd(doc any, segs []pathSegment, value any) (any, error) {
+	if len(segs) == 0 {
+		return value, nil
+	}
+	parentSegs, last := segs[:len(segs)-1], segs[len(segs)-1]
+	parent, ok := lookupSegments(doc, parentSegs)
+	if !ok {
+		return nil, fmt.Errorf("parent not found")
+	}
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[last.key] = value
+		return doc, nil
+	case []any:
+		idx := len(p)
+		if last.key != "-" {
+			n, ok := parseIndex(last.key)
+			if !ok || n > len(p) {
+				return nil, fmt.Errorf("index out of bounds: %s", last.key)
+			}
+			idx = n
+		}
+		grown := slices.Insert(p, idx, value)
+		if len(parentSegs) == 0 {
+			return grown, nil
+		}
+		return setAt(doc, parentSegs, grown)
+	default:
+		return nil, fmt.Errorf("parent is not a map or slice")
+	}
+}
+
+// lookupSegments reports the value at segs and whether it exists.
+func lookupSegments(doc any, segs []pathSegment) (any, bool) {
+	current := doc
+	for _, seg := range segs {
+		if m, ok := current.(map[string]any); ok {
+			v, exists := m[seg.key]
+			if !exists {
+				return nil, false
+			}
+			current = v
+			continue
+		}
+		next, _, err := stepInto(current, seg)
+		if err != nil {
+			return nil, false
+		}
+		current = next
+	}
+	return current, true
+}
+
+// ------------------- Diff -------------------
+
+// Diff returns a minimal JSON Patch that turns a into b. Slices are aligned
+// on their longest common subsequence, so inserting or removing one element
+// anywhere costs one operation; changed elements are diffed in place.
+func Diff(a, b TreeMapImpl) []PatchOperation {
+	var ops []PatchOperation
+	diffValues(nil, a.getValue(), b.getValue(), &ops)
+	return ops
+}
+
+func diffValues(segs []pathSegment, a, b any, ops *[]PatchOperation) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := append(segs[:len(segs):len(segs)], keySegment(k))
+			aval, inA := av[k]
+			bval, inB := bv[k]
+			switch {
+			case !inB:
+				*ops = append(*ops, PatchOperation{Op: "remove", Path: formatPointer(child)})
+			case !inA:
+				*ops = append(*ops, PatchOperation{Op: "add", Path: formatPointer(child), Value: deepClone(bval)})
+			default:
+				diffValues(child, aval, bval, ops)
+			}
+		}
+		return
+
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		diffSlices(segs, av, bv, ops)
+		return
+	}
+
+	if !valuesEqual(a, b, true) {
+		*ops = append(*ops, PatchOperation{Op: "replace", Path: formatPointer(segs), Value: deepClone(b)})
+	}
+}
+
+// maxDiffCells bounds the LCS table of diffSlices; longer slices fall back
+// to an index by index diff of the part between their common prefix and
+// suffix.
+const maxDiffCells = 1 << 20
+
+// diffSlices emits the operations that turn a into b, using an LCS table
+// over the elements that differ between their common prefix and suffix.
+// pos tracks the index in the slice as it looks after the ops so far.
+func diffSlices(segs []pathSegment, a, b []any, ops *[]PatchOperation) {
+	at := func(i int) string {
+		return formatPointer(append(segs[:len(segs):len(segs)], indexSegment(i)))
+	}
+	equal := func(x, y any) bool { return valuesEqual(x, y, true) }
+
+	pre := 0
+	for pre < len(a) && pre < len(b) && equal(a[pre], b[pre]) {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && equal(a[len(a)-1-suf], b[len(b)-1-suf]) {
+		suf++
+	}
+	am, bm := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	n, m := len(am), len(bm)
+
+	// lcs[i][j] is the LCS length of am[i:] and bm[j:]
+	var lcs [][]int
+	if (n+1)*(m+1) <= maxDiffCells {
+		lcs = make([][]int, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if equal(am[i], bm[j]) {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+	}
+
+	pos := pre
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case lcs != nil && i < n && j < m && equal(am[i], bm[j]):
+			i, j, pos = i+1, j+1, pos+1
+		case i < n && j < m && (lcs == nil || lcs[i+1][j+1] == lcs[i][j]):
+			// am[i] became bm[j]
+			diffValues(append(segs[:len(segs):len(segs)], indexSegment(pos)), am[i], bm[j], ops)
+			i, j, pos = i+1, j+1, pos+1
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			*ops = append(*ops, PatchOperation{Op: "add", Path: at(pos), Value: deepClone(bm[j])})
+			j, pos = j+1, pos+1
+		default:
+			*ops = append(*ops, PatchOperation{Op: "remove", Path: at(pos)})
+			i++
+		}
+	}
+}
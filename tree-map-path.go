@@ -0,0 +1,342 @@
+package goutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ------------------- Path Grammar -------------------
+//
+//	user.name            dotted keys
+//	user\.email          backslash escapes a dot (or any other char)
+//	labels["app.io/n"]   quoted keys inside brackets, single or double quotes
+//	items[0].name        bracket index
+//	a."b.c".d            quoted dotted segment
+//	items.-              append token (Set only)
+//	users.*.email        wildcard over map values or slice elements (Query only)
+//	users[*]             bracket wildcard (Query only)
+//	..id                 recursive descent (Query only)
+//	items[1:3]           slice range with optional step, [start:end:step] (Query only)
+//	items[?(@.qty > 1)]  filter expression, see tree-map-filter.go (Query only)
+
+type segmentKind int
+
+const (
+	segmentKey segmentKind = iota
+	segmentIndex
+	segmentWildcard
+	segmentRecursive
+	segmentSlice
+	segmentFilter
+)
+
+type pathSegment struct {
+	kind   segmentKind
+	key    string
+	index  int
+	quoted bool
+
+	// segmentSlice bounds
+	start, end, step int
+	hasStart, hasEnd bool
+
+	filter *filterNode
+}
+
+// isIndexLike reports whether a missing container for seg should be created
+// as a slice: bracket indexes, unquoted numeric keys and the `-` append token.
+func (s pathSegment) isIndexLike() bool {
+	if s.kind == segmentIndex {
+		return true
+	}
+	if s.quoted {
+		return false
+	}
+	if s.key == "-" {
+		return true
+	}
+	_, ok := parseIndex(s.key)
+	return ok
+}
+
+func (s pathSegment) isConcrete() bool {
+	return s.kind == segmentKey || s.kind == segmentIndex
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	p := &pathParser{src: path}
+	return p.parse()
+}
+
+// parseConcretePath parses path and rejects query-only segments.
+func parseConcretePath(path string) ([]pathSegment, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		if !seg.isConcrete() {
+			return nil, fmt.Errorf("path %q has wildcards, use Query/SetAll/DeleteAll", path)
+		}
+	}
+	return segs, nil
+}
+
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) parse() ([]pathSegment, error) {
+	segs := make([]pathSegment, 0, strings.Count(p.src, ".")+strings.Count(p.src, "[")+1)
+
+	for first := true; first || p.pos < len(p.src); first = false {
+		var (
+			seg pathSegment
+			err error
+		)
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], ".."):
+			p.pos += 2
+			if p.pos >= len(p.src) {
+				return nil, fmt.Errorf("invalid path %q: '..' must be followed by a segment", p.src)
+			}
+			segs = append(segs, pathSegment{kind: segmentRecursive})
+			if p.peek() == '[' {
+				seg, err = p.parseBracket()
+			} else {
+				seg, err = p.parseDotted()
+			}
+		case p.peek() == '[':
+			seg, err = p.parseBracket()
+		case first:
+			seg, err = p.parseDotted()
+		case p.peek() == '.':
+			p.pos++
+			seg, err = p.parseDotted()
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q at %d", p.src, p.src[p.pos], p.pos)
+		}
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+	return segs, nil
+}
+
+func (p *pathParser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// parseDotted reads a key until the next unescaped '.' or '['.
+func (p *pathParser) parseDotted() (pathSegment, error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		key, err := p.parseQuoted()
+		if err != nil {
+			return pathSegment{}, err
+		}
+		if c := p.peek(); c != 0 && c != '.' && c != '[' {
+			return pathSegment{}, fmt.Errorf("invalid path %q: unexpected %q after quoted key", p.src, c)
+		}
+		return pathSegment{kind: segmentKey, key: key, quoted: true}, nil
+	}
+
+	var sb strings.Builder
+	escaped := false
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '\\' {
+			if p.pos+1 >= len(p.src) {
+				return pathSegment{}, fmt.Errorf("invalid path %q: dangling escape", p.src)
+			}
+			sb.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+			escaped = true
+			continue
+		}
+		if c == '.' || c == '[' {
+			break
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	if sb.String() == "*" && !escaped {
+		return pathSegment{kind: segmentWildcard}, nil
+	}
+	return pathSegment{kind: segmentKey, key: sb.String(), quoted: escaped}, nil
+}
+
+// parseBracket reads `[0]`, `["key"]`, `['key']`, `[*]`, `[start:end:step]`
+// or `[?(expr)]`.
+func (p *pathParser) parseBracket() (pathSegment, error) {
+	open := p.pos
+	p.pos++ // '['
+	var seg pathSegment
+
+	if p.peek() == '?' {
+		end, ok := skipBracket(p.src, open)
+		if !ok {
+			return pathSegment{}, fmt.Errorf("invalid path %q: unclosed filter", p.src)
+		}
+		expr, err := parseFilter(p.src[p.pos+1 : end-1])
+		if err != nil {
+			return pathSegment{}, err
+		}
+		seg = pathSegment{kind: segmentFilter, filter: expr}
+		p.pos = end - 1
+	} else if c := p.peek(); c == '"' || c == '\'' {
+		key, err := p.parseQuoted()
+		if err != nil {
+			return pathSegment{}, err
+		}
+		seg = pathSegment{kind: segmentKey, key: key, quoted: true}
+	} else {
+		end := strings.IndexByte(p.src[p.pos:], ']')
+		if end < 0 {
+			return pathSegment{}, fmt.Errorf("invalid path %q: unclosed '['", p.src)
+		}
+		raw := strings.TrimSpace(p.src[p.pos : p.pos+end])
+		p.pos += end
+
+		switch {
+		case raw == "*":
+			seg = pathSegment{kind: segmentWildcard}
+		case strings.Contains(raw, ":"):
+			s, err := parseSliceRange(raw)
+			if err != nil {
+				return pathSegment{}, fmt.Errorf("invalid path %q: %w", p.src, err)
+			}
+			seg = s
+		default:
+			idx, ok := parseIndex(raw)
+			if !ok {
+				return pathSegment{}, fmt.Errorf("invalid path %q: bad index %q", p.src, raw)
+			}
+			seg = pathSegment{kind: segmentIndex, key: raw, index: idx}
+		}
+	}
+
+	if p.peek() != ']' {
+		return pathSegment{}, fmt.Errorf("invalid path %q: expected ']' at %d", p.src, p.pos)
+	}
+	p.pos++
+	return seg, nil
+}
+
+func (p *pathParser) parseQuoted() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.src):
+			sb.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("invalid path %q: unterminated quoted key", p.src)
+}
+
+func parseSliceRange(raw string) (pathSegment, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) > 3 {
+		return pathSegment{}, fmt.Errorf("bad slice %q", raw)
+	}
+	seg := pathSegment{kind: segmentSlice, step: 1}
+
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return pathSegment{}, fmt.Errorf("bad slice %q", raw)
+		}
+		switch i {
+		case 0:
+			seg.start, seg.hasStart = n, true
+		case 1:
+			seg.end, seg.hasEnd = n, true
+		case 2:
+			if n <= 0 {
+				return pathSegment{}, fmt.Errorf("bad slice step %q", raw)
+			}
+			seg.step = n
+		}
+	}
+	return seg, nil
+}
+
+// bounds resolves a slice segment against a length, python style.
+func (s pathSegment) bounds(length int) (int, int) {
+	clamp := func(n, def int, has bool) int {
+		if !has {
+			return def
+		}
+		if n < 0 {
+			n += length
+		}
+		return max(0, min(n, length))
+	}
+	return clamp(s.start, 0, s.hasStart), clamp(s.end, length, s.hasEnd)
+}
+
+// ------------------- Path Rendering -------------------
+
+func formatPath(segs []pathSegment) string {
+	var sb strings.Builder
+	for i, seg := range segs {
+		switch seg.kind {
+		case segmentIndex:
+			sb.WriteString("[" + strconv.Itoa(seg.index) + "]")
+		default:
+			if isPlainKey(seg.key) {
+				if i > 0 {
+					sb.WriteByte('.')
+				}
+				sb.WriteString(seg.key)
+			} else {
+				sb.WriteString(`["` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(seg.key) + `"]`)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// joinRawPath joins two rendered paths.
+func joinRawPath(base, path string) string {
+	switch {
+	case base == "":
+		return path
+	case path == "":
+		return base
+	case strings.HasPrefix(path, "["):
+		return base + path
+	}
+	return base + "." + path
+}
+
+// isPlainKey reports whether key can be written as a bare dotted segment
+// and parse back to the same map key.
+func isPlainKey(key string) bool {
+	if key == "" || key == "*" || key == "-" || strings.ContainsAny(key, `.[]\"'`) {
+		return false
+	}
+	if _, err := strconv.Atoi(key); err == nil {
+		return false
+	}
+	return true
+}
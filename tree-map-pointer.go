@@ -0,0 +1,96 @@
+package goutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ------------------- JSON Pointer (RFC 6901) -------------------
+
+var (
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// GetPointer resolves an RFC 6901 pointer such as `/users/0/name`.
+// The empty pointer refers to the current node.
+func (d *TreeMap) GetPointer(pointer string) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	segs, err := parsePointer(pointer)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	return d.getSegments(segs)
+}
+
+// SetPointer writes value at pointer with the same rules as Set, `-` appends.
+func (d *TreeMap) SetPointer(pointer string, value any) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	segs, err := parsePointer(pointer)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	return d.setSegments(segs, value)
+}
+
+// DeletePointer removes the value at pointer and returns it, like Delete.
+func (d *TreeMap) DeletePointer(pointer string) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	segs, err := parsePointer(pointer)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+	return d.deleteSegments(segs)
+}
+
+// Pointer returns the location of this node as an RFC 6901 pointer.
+func (d *TreeMap) Pointer() string {
+	return formatPointer(d.path)
+}
+
+func parsePointer(pointer string) ([]pathSegment, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q: must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	segs := make([]pathSegment, len(tokens))
+	for i, tok := range tokens {
+		if err := checkPointerEscapes(tok); err != nil {
+			return nil, fmt.Errorf("invalid json pointer %q: %w", pointer, err)
+		}
+		segs[i] = pathSegment{kind: segmentKey, key: pointerUnescaper.Replace(tok)}
+	}
+	return segs, nil
+}
+
+func checkPointerEscapes(tok string) error {
+	for i := 0; i < len(tok); i++ {
+		if tok[i] == '~' && (i+1 >= len(tok) || (tok[i+1] != '0' && tok[i+1] != '1')) {
+			return fmt.Errorf("bad escape at %d", i)
+		}
+	}
+	return nil
+}
+
+func formatPointer(segs []pathSegment) string {
+	var sb strings.Builder
+	for _, seg := range segs {
+		sb.WriteByte('/')
+		if seg.kind == segmentIndex {
+			sb.WriteString(strconv.Itoa(seg.index))
+			continue
+		}
+		sb.WriteString(pointerEscaper.Replace(seg.key))
+	}
+	return sb.String()
+}
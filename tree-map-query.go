@@ -0,0 +1,213 @@
+package goutils
+
+import (
+	"slices"
+	"sort"
+)
+
+// ------------------- Query / SetAll / DeleteAll -------------------
+
+// filterEnv evaluates filters of writes against d itself.
+func (d *TreeMap) filterEnv() filterEnv {
+	return filterEnv{root: d.value, conv: d.converter()}
+}
+
+type pathMatch struct {
+	segs  []pathSegment
+	value any
+}
+
+// Query resolves a path that may contain wildcards (`*`), recursive descent
+// (`..`), slice ranges (`[1:3]`) and filters (`[?(@.total > 100)]`).
+// Every match carries its concrete Path().
+func (d *TreeMap) Query(path string) ([]TreeMapImpl, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root := d.value
+	if d.root != nil {
+		root = d.root.getValue()
+	}
+
+	var result []TreeMapImpl
+	for _, m := range collectMatches(filterEnv{root: root, conv: d.converter()}, d.value, segs) {
+		full := append(d.path[:len(d.path):len(d.path)], m.segs...)
+		result = append(result, &TreeMap{value: m.value, root: d.root, path: full})
+	}
+	return result, nil
+}
+
+// SetAll writes value at every match of path. When the last segment is a
+// plain key or index it is created on each matched parent, so
+// `users.*.active` also sets users that have no `active` yet. When any
+// target cannot be written, nothing is written.
+func (d *TreeMap) SetAll(path string, value any) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	segs, err := parsePath(path)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+
+	var targets [][]pathSegment
+	if last := segs[len(segs)-1]; last.isConcrete() {
+		for _, m := range collectMatches(d.filterEnv(), d.value, segs[:len(segs)-1]) {
+			if isContainer(m.value) {
+				targets = append(targets, append(m.segs, last))
+			}
+		}
+	} else {
+		for _, m := range collectMatches(d.filterEnv(), d.value, segs) {
+			targets = append(targets, m.segs)
+		}
+	}
+
+	// Rehearse on a copy first so a failing target leaves the tree as it was.
+	if _, err := setTargets(deepClone(d.value), targets, value); err != nil {
+		return &TreeMap{err: prefixErrorPath(err, d.path)}
+	}
+	old := d.value
+	d.value, _ = setTargets(d.value, targets, value)
+	d.attach(old)
+	return d
+}
+
+func setTargets(current any, targets [][]pathSegment, value any) (any, error) {
+	for _, target := range targets {
+		if len(target) == 0 {
+			continue
+		}
+		updated, err := setAt(current, target, normalizeToDefault(value))
+		if err != nil {
+			return nil, err
+		}
+		current = updated
+	}
+	return current, nil
+}
+
+// DeleteAll removes every match of path and returns the root treemap.
+// Matches are removed deepest/last first so slice indexes stay valid.
+func (d *TreeMap) DeleteAll(path string) TreeMapImpl {
+	if d.err != nil {
+		return d
+	}
+	segs, err := parsePath(path)
+	if err != nil {
+		return &TreeMap{err: err}
+	}
+
+	old := d.value
+	defer d.attach(old)
+	matches := collectMatches(d.filterEnv(), d.value, segs)
+	for _, m := range slices.Backward(matches) {
+		if len(m.segs) == 0 {
+			continue
+		}
+		updated, _, err := deleteAt(d.value, m.segs)
+		if err != nil {
+			// already removed together with an ancestor match
+			continue
+		}
+		d.value = updated
+	}
+	return d
+}
+
+// collectMatches resolves segs against value. env carries what `$` refers
+// to inside filter expressions and the Converter they coerce with.
+func collectMatches(env filterEnv, value any, segs []pathSegment) []pathMatch {
+	mt := &matcher{env: env}
+	mt.walk(value, segs, nil)
+	return mt.out
+}
+
+type matcher struct {
+	env filterEnv
+	out []pathMatch
+}
+
+func (mt *matcher) walk(current any, segs []pathSegment, prefix []pathSegment) {
+	if len(segs) == 0 {
+		mt.out = append(mt.out, pathMatch{segs: slices.Clone(prefix), value: current})
+		return
+	}
+	seg, rest := segs[0], segs[1:]
+
+	switch seg.kind {
+	case segmentWildcard:
+		for _, c := range childrenOf(current) {
+			mt.walk(c.value, rest, append(prefix, c.seg))
+		}
+
+	case segmentRecursive:
+		mt.walk(current, rest, prefix)
+		for _, c := range childrenOf(current) {
+			mt.walk(c.value, segs, append(prefix, c.seg))
+		}
+
+	case segmentSlice:
+		list, ok := current.([]any)
+		if !ok {
+			return
+		}
+		start, end := seg.bounds(len(list))
+		for i := start; i < end; i += seg.step {
+			mt.walk(list[i], rest, append(prefix, indexSegment(i)))
+		}
+
+	case segmentFilter:
+		for _, c := range childrenOf(current) {
+			if seg.filter.matches(c.value, mt.env) {
+				mt.walk(c.value, rest, append(prefix, c.seg))
+			}
+		}
+
+	default:
+		if m, ok := current.(map[string]any); ok {
+			if _, exists := m[seg.key]; !exists {
+				return
+			}
+		}
+		next, resolved, err := stepInto(current, seg)
+		if err != nil {
+			return
+		}
+		mt.walk(next, rest, append(prefix, resolved))
+	}
+}
+
+type childEntry struct {
+	seg   pathSegment
+	value any
+}
+
+// childrenOf lists the direct children of a container, map keys sorted.
+func childrenOf(v any) []childEntry {
+	switch c := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(c))
+		for k := range c {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]childEntry, len(keys))
+		for i, k := range keys {
+			out[i] = childEntry{seg: keySegment(k), value: c[k]}
+		}
+		return out
+	case []any:
+		out := make([]childEntry, len(c))
+		for i, e := range c {
+			out[i] = childEntry{seg: indexSegment(i), value: e}
+		}
+		return out
+	}
+	return nil
+}
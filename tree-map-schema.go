@@ -0,0 +1,449 @@
+package goutils
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ------------------- JSON Schema Validation -------------------
+//
+// A practical subset of JSON Schema 2020-12: type, enum, const, numeric and
+// length bounds, pattern, format, required, properties, patternProperties,
+// additionalProperties, min/maxProperties, prefixItems, items, min/maxItems,
+// uniqueItems, allOf, anyOf, oneOf, not and local $ref (`#/$defs/...`).
+
+type SchemaViolation struct {
+	Pointer string // JSON Pointer of the offending value, "" for the root
+	Keyword string
+	Message string
+}
+
+func (v SchemaViolation) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", displayPointer(v.Pointer), v.Message, v.Keyword)
+}
+
+type ValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Error()
+	}
+	return "schema validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the tree against schema and returns a *ValidationError
+// listing every violation, or nil when the document is valid.
+func (d *TreeMap) Validate(schema TreeMapImpl) error {
+	if d.err != nil {
+		return d.err
+	}
+	if err := schema.Err(); err != nil {
+		return err
+	}
+
+	v := &schemaValidator{root: schema.getValue(), patterns: map[string]*regexp.Regexp{}}
+	v.validate(v.root, d.value, nil, 0)
+	if len(v.out) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.out}
+}
+
+const maxSchemaDepth = 64
+
+type schemaValidator struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+	out      []SchemaViolation
+}
+
+func (v *schemaValidator) fail(at []pathSegment, keyword, format string, args ...any) {
+	v.out = append(v.out, SchemaViolation{
+		Pointer: formatPointer(at),
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// matches runs schema against value in isolation and reports whether it passed.
+func (v *schemaValidator) matches(schema, value any, at []pathSegment, depth int) bool {
+	sub := &schemaValidator{root: v.root, patterns: v.patterns}
+	sub.validate(schema, value, at, depth)
+	return len(sub.out) == 0
+}
+
+func (v *schemaValidator) validate(schema, value any, at []pathSegment, depth int) {
+	if depth > maxSchemaDepth {
+		v.fail(at, "$ref", "schema nesting too deep")
+		return
+	}
+
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(at, "false", "no value is allowed here")
+		}
+		return
+	case map[string]any:
+		v.validateMap(s, value, at, depth)
+	default:
+		v.fail(at, "schema", "invalid schema of type %T", schema)
+	}
+}
+
+func (v *schemaValidator) validateMap(s map[string]any, value any, at []pathSegment, depth int) {
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := v.resolveRef(ref)
+		if err != nil {
+			v.fail(at, "$ref", "%v", err)
+		} else {
+			v.validate(target, value, at, depth+1)
+		}
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, value) {
+		v.fail(at, "type", "expected %s, got %s", describeTypes(t), jsonType(value))
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if valuesEqual(value, e, true) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(at, "enum", "value %v is not one of %v", value, enum)
+		}
+	}
+	if c, ok := s["const"]; ok && !valuesEqual(value, c, true) {
+		v.fail(at, "const", "value %v must be %v", value, c)
+	}
+
+	v.validateCombinators(s, value, at, depth)
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(s, val, at)
+	case map[string]any:
+		v.validateObject(s, val, at, depth)
+	case []any:
+		v.validateArray(s, val, at, depth)
+	default:
+		if isNumber(value) {
+			f, _ := (&TreeMap{value: value}).AsFloat()
+			v.validateNumber(s, f, at)
+		}
+	}
+}
+
+func (v *schemaValidator) validateCombinators(s map[string]any, value any, at []pathSegment, depth int) {
+	if allOf, ok := s["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			v.validate(sub, value, at, depth+1)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		passed := false
+		for _, sub := range anyOf {
+			if v.matches(sub, value, at, depth+1) {
+				passed = true
+				break
+			}
+		}
+		if !passed {
+			v.fail(at, "anyOf", "value does not match any schema")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		count := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, value, at, depth+1) {
+				count++
+			}
+		}
+		if count != 1 {
+			v.fail(at, "oneOf", "value matches %d schemas, expected exactly one", count)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, value, at, depth+1) {
+		v.fail(at, "not", "value must not match schema")
+	}
+}
+
+func (v *schemaValidator) validateNumber(s map[string]any, n float64, at []pathSegment) {
+	if limit, ok := schemaNumber(s["minimum"]); ok && n < limit {
+		v.fail(at, "minimum", "%v is less than %v", n, limit)
+	}
+	if limit, ok := schemaNumber(s["maximum"]); ok && n > limit {
+		v.fail(at, "maximum", "%v is greater than %v", n, limit)
+	}
+	if limit, ok := schemaNumber(s["exclusiveMinimum"]); ok && n <= limit {
+		v.fail(at, "exclusiveMinimum", "%v must be greater than %v", n, limit)
+	}
+	if limit, ok := schemaNumber(s["exclusiveMaximum"]); ok && n >= limit {
+		v.fail(at, "exclusiveMaximum", "%v must be less than %v", n, limit)
+	}
+	if m, ok := schemaNumber(s["multipleOf"]); ok && m > 0 {
+		if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(at, "multipleOf", "%v is not a multiple of %v", n, m)
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(s map[string]any, str string, at []pathSegment) {
+	length := utf8.RuneCountInString(str)
+	if limit, ok := schemaNumber(s["minLength"]); ok && float64(length) < limit {
+		v.fail(at, "minLength", "length %d is less than %v", length, limit)
+	}
+	if limit, ok := schemaNumber(s["maxLength"]); ok && float64(length) > limit {
+		v.fail(at, "maxLength", "length %d is greater than %v", length, limit)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := v.compile(pattern)
+		switch {
+		case err != nil:
+			v.fail(at, "pattern", "invalid pattern %q: %v", pattern, err)
+		case !re.MatchString(str):
+			v.fail(at, "pattern", "%q does not match %q", str, pattern)
+		}
+	}
+	if format, ok := s["format"].(string); ok && !checkFormat(format, str) {
+		v.fail(at, "format", "%q is not a valid %s", str, format)
+	}
+}
+
+func (v *schemaValidator) validateObject(s map[string]any, obj map[string]any, at []pathSegment, depth int) {
+	child := func(k string) []pathSegment {
+		return append(at[:len(at):len(at)], keySegment(k))
+	}
+
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, exists := obj[name]; !exists {
+					v.fail(child(name), "required", "missing required property %q", name)
+				}
+			}
+		}
+	}
+	if limit, ok := schemaNumber(s["minProperties"]); ok && float64(len(obj)) < limit {
+		v.fail(at, "minProperties", "has %d properties, expected at least %v", len(obj), limit)
+	}
+	if limit, ok := schemaNumber(s["maxProperties"]); ok && float64(len(obj)) > limit {
+		v.fail(at, "maxProperties", "has %d properties, expected at most %v", len(obj), limit)
+	}
+
+	props, _ := s["properties"].(map[string]any)
+	patternProps, _ := s["patternProperties"].(map[string]any)
+	additional, hasAdditional := s["additionalProperties"]
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		matched := false
+		if sub, ok := props[k]; ok {
+			matched = true
+			v.validate(sub, obj[k], child(k), depth+1)
+		}
+		for pattern, sub := range patternProps {
+			if re, err := v.compile(pattern); err == nil && re.MatchString(k) {
+				matched = true
+				v.validate(sub, obj[k], child(k), depth+1)
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			v.fail(child(k), "additionalProperties", "property %q is not allowed", k)
+			continue
+		}
+		v.validate(additional, obj[k], child(k), depth+1)
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]any, list []any, at []pathSegment, depth int) {
+	if limit, ok := schemaNumber(s["minItems"]); ok && float64(len(list)) < limit {
+		v.fail(at, "minItems", "has %d items, expected at least %v", len(list), limit)
+	}
+	if limit, ok := schemaNumber(s["maxItems"]); ok && float64(len(list)) > limit {
+		v.fail(at, "maxItems", "has %d items, expected at most %v", len(list), limit)
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+	outer:
+		for i := range list {
+			for j := i + 1; j < len(list); j++ {
+				if valuesEqual(list[i], list[j], true) {
+					v.fail(at, "uniqueItems", "items %d and %d are equal", i, j)
+					break outer
+				}
+			}
+		}
+	}
+
+	start := 0
+	if prefix, ok := s["prefixItems"].([]any); ok {
+		for i := 0; i < len(prefix) && i < len(list); i++ {
+			v.validate(prefix[i], list[i], append(at[:len(at):len(at)], indexSegment(i)), depth+1)
+		}
+		start = len(prefix)
+	}
+	if items, ok := s["items"]; ok {
+		for i := start; i < len(list); i++ {
+			v.validate(items, list[i], append(at[:len(at):len(at)], indexSegment(i)), depth+1)
+		}
+	}
+}
+
+// resolveRef follows a same-document reference such as `#` or `#/$defs/user`.
+func (v *schemaValidator) resolveRef(ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local $ref is supported, got %q", ref)
+	}
+	ptr, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q", ref)
+	}
+	segs, err := parsePointer(ptr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+	target, ok := lookupSegments(v.root, segs)
+	if !ok {
+		return nil, fmt.Errorf("unresolved $ref %q", ref)
+	}
+	return target, nil
+}
+
+func (v *schemaValidator) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+// ------------------- Schema Helpers -------------------
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	if isNumber(value) {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func matchesType(t any, value any) bool {
+	switch tt := t.(type) {
+	case string:
+		actual := jsonType(value)
+		if tt == "integer" {
+			f, err := (&TreeMap{value: value}).AsFloat()
+			return actual == "number" && err == nil && f == math.Trunc(f)
+		}
+		return actual == tt
+	case []any:
+		for _, each := range tt {
+			if matchesType(each, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func describeTypes(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, len(list))
+		for i, n := range list {
+			names[i] = fmt.Sprint(n)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func schemaNumber(v any) (float64, bool) {
+	if !isNumber(v) {
+		return 0, false
+	}
+	f, err := (&TreeMap{value: v}).AsFloat()
+	return f, err == nil
+}
+
+var hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// checkFormat validates the common `format` values; unknown formats pass.
+func checkFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuid.Validate(s) == nil && len(s) == 36
+	case "ipv4":
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is4()
+	case "ipv6":
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is6()
+	case "hostname":
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	}
+	return true
+}
+
+func displayPointer(ptr string) string {
+	if ptr == "" {
+		return "/"
+	}
+	return ptr
+}
@@ -0,0 +1,272 @@
+package goutils
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// ------------------- Time / Duration -------------------
+
+// AsTime parses RFC 3339 strings (after trying layouts, if given) and unix
+// timestamps, as numbers or numeric strings. Timestamps of 1e12 and above
+// are read as milliseconds, smaller ones as seconds.
+func (d *TreeMap) AsTime(layouts ...string) (time.Time, error) {
+	if d.err != nil {
+		return time.Time{}, d.err
+	}
+	if !isNumber(d.value) {
+		if _, ok := d.value.(string); !ok {
+			return time.Time{}, mismatch(d.Path(), "time", d.value)
+		}
+	}
+	t, err := parseTime(d.value, layouts)
+	return t, d.valueError(err)
+}
+
+func parseTime(v any, layouts []string) (time.Time, error) {
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", s)
+		}
+		v = f
+	}
+	if !isNumber(v) {
+		return time.Time{}, fmt.Errorf("cannot convert to time: %T", v)
+	}
+	f := toFloat64(reflect.ValueOf(v))
+	if math.Abs(f) >= 1e12 {
+		return time.UnixMilli(int64(f)), nil
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
+
+// AsDuration returns a stored time.Duration as is and parses Go duration
+// strings ("1m30s") and numbers of seconds.
+func (d *TreeMap) AsDuration() (time.Duration, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	if dur, ok := d.value.(time.Duration); ok {
+		return dur, nil
+	}
+	if _, ok := d.value.(string); !ok && !isNumber(d.value) {
+		return 0, mismatch(d.Path(), "duration", d.value)
+	}
+	dur, err := toDuration(d.value)
+	return dur, d.valueError(err)
+}
+
+// ------------------- Network / Identifiers -------------------
+
+// AsURL parses an absolute URL; values without a scheme are rejected.
+func (d *TreeMap) AsURL() (*url.URL, error) {
+	s, err := d.asText("url")
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(s)
+	if err == nil && u.Scheme == "" {
+		err = fmt.Errorf("url %q has no scheme", s)
+	}
+	if err != nil {
+		return nil, d.valueError(err)
+	}
+	return u, nil
+}
+
+func (d *TreeMap) AsIP() (netip.Addr, error) {
+	s, err := d.asText("ip")
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	ip, err := netip.ParseAddr(s)
+	return ip, d.valueError(err)
+}
+
+// AsCIDR parses a prefix such as "10.0.0.0/8".
+func (d *TreeMap) AsCIDR() (netip.Prefix, error) {
+	s, err := d.asText("cidr")
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	p, err := netip.ParsePrefix(s)
+	return p, d.valueError(err)
+}
+
+func (d *TreeMap) AsUUID() (uuid.UUID, error) {
+	s, err := d.asText("uuid")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id, err := uuid.Parse(s)
+	return id, d.valueError(err)
+}
+
+// asText returns the trimmed string value or a type mismatch.
+func (d *TreeMap) asText(want string) (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+	s, ok := d.value.(string)
+	if !ok {
+		return "", mismatch(d.Path(), want, d.value)
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// ------------------- Byte Sizes -------------------
+
+var byteUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "kib": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mib": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gib": 1 << 30,
+	"t": 1e12, "tb": 1e12, "tib": 1 << 40,
+	"p": 1e15, "pb": 1e15, "pib": 1 << 50,
+}
+
+// AsByteSize returns a number of bytes from numbers or strings such as
+// "512", "10MiB", "1.5 GB" (decimal units) or "64k". Units are not case
+// sensitive.
+func (d *TreeMap) AsByteSize() (int64, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	if isNumber(d.value) {
+		n, err := toInt64(reflect.ValueOf(d.value))
+		return n, d.valueError(err)
+	}
+	s, ok := d.value.(string)
+	if !ok {
+		return 0, mismatch(d.Path(), "byte size", d.value)
+	}
+	n, err := parseByteSize(s)
+	return n, d.valueError(err)
+}
+
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsSpace(r)
+	})
+	num, unit := s, ""
+	if split >= 0 {
+		num, unit = s[:split], strings.ToLower(strings.TrimSpace(s[split:]))
+	}
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown byte size unit %q", unit)
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	size := math.Round(f * mult)
+	if size >= math.MaxInt64 {
+		return 0, ErrOutOfRange
+	}
+	return int64(size), nil
+}
+
+// ------------------- Default Fallbacks -------------------
+
+func (d *TreeMap) AsTimeOr(def time.Time, layouts ...string) time.Time {
+	return orDefault(def)(d.AsTime(layouts...))
+}
+
+func (d *TreeMap) AsDurationOr(def time.Duration) time.Duration {
+	return orDefault(def)(d.AsDuration())
+}
+
+func (d *TreeMap) AsURLOr(def *url.URL) *url.URL {
+	return orDefault(def)(d.AsURL())
+}
+
+func (d *TreeMap) AsIPOr(def netip.Addr) netip.Addr {
+	return orDefault(def)(d.AsIP())
+}
+
+func (d *TreeMap) AsCIDROr(def netip.Prefix) netip.Prefix {
+	return orDefault(def)(d.AsCIDR())
+}
+
+func (d *TreeMap) AsUUIDOr(def uuid.UUID) uuid.UUID {
+	return orDefault(def)(d.AsUUID())
+}
+
+func (d *TreeMap) AsByteSizeOr(def int64) int64 {
+	return orDefault(def)(d.AsByteSize())
+}
+
+func orDefault[T any](def T) func(T, error) T {
+	return func(v T, err error) T {
+		if err != nil {
+			return def
+		}
+		return v
+	}
+}
+
+// ------------------- Slices -------------------
+// Like AsIntSlice, elements that fail to convert hold the zero value.
+
+func (d *TreeMap) AsTimeSlice(layouts ...string) []time.Time {
+	return sliceOf(d, func(e TreeMapImpl) (time.Time, error) { return e.AsTime(layouts...) })
+}
+
+func (d *TreeMap) AsDurationSlice() []time.Duration {
+	return sliceOf(d, TreeMapImpl.AsDuration)
+}
+
+func (d *TreeMap) AsURLSlice() []*url.URL {
+	return sliceOf(d, TreeMapImpl.AsURL)
+}
+
+func (d *TreeMap) AsIPSlice() []netip.Addr {
+	return sliceOf(d, TreeMapImpl.AsIP)
+}
+
+func (d *TreeMap) AsCIDRSlice() []netip.Prefix {
+	return sliceOf(d, TreeMapImpl.AsCIDR)
+}
+
+func (d *TreeMap) AsUUIDSlice() []uuid.UUID {
+	return sliceOf(d, TreeMapImpl.AsUUID)
+}
+
+func (d *TreeMap) AsByteSizeSlice() []int64 {
+	return sliceOf(d, TreeMapImpl.AsByteSize)
+}
+
+func sliceOf[T any](d *TreeMap, conv func(TreeMapImpl) (T, error)) []T {
+	v, err := d.AsSlice()
+	if err != nil {
+		return nil
+	}
+	out := make([]T, 0, len(v))
+	for _, e := range v {
+		item, _ := conv(e)
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package goutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ------------------- Structs -------------------
+
+// FromStruct builds a tree from a struct or pointer to struct, following
+// encoding/json conventions: json tag names, omitempty, omitzero, "-" and
+// promoted fields of exported embedded structs. time.Time becomes an
+// RFC 3339 string and json.Marshaler values are replaced by their decoded
+// JSON output.
+// NewTreeMap and Set normalize struct values the same way.
+func FromStruct(v any) TreeMapImpl {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return &TreeMap{err: fmt.Errorf("FromStruct: expected a struct, got %T", v)}
+	}
+	return NewTreeMap(v)
+}
+
+// normalizeStruct writes the exported fields of rv into out. Exported
+// embedded structs go first so the outer struct's fields win on name clashes.
+func normalizeStruct(rv reflect.Value, out map[string]any) {
+	t := rv.Type()
+	var own []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				normalizeStruct(fv, out)
+				continue
+			}
+		}
+		own = append(own, i)
+	}
+
+	for _, i := range own {
+		field, fv := t.Field(i), rv.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		if hasTagOption(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+		if hasTagOption(opts, "omitzero") && fv.IsZero() {
+			continue
+		}
+		out[name] = normalizeToDefault(fv.Interface())
+	}
+}
+
+// normalizeMarshaler decodes the JSON output of m with the same number rules
+// as NewTreeMapFromJSON, keeping m as an opaque value when it fails to
+// marshal.
+func normalizeMarshaler(m json.Marshaler) any {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return m
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return m
+	}
+	return decodeNumbers(out, NumberInteger)
+}
+
+func hasTagOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue mirrors the omitempty rules of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
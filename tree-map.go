@@ -10,6 +10,8 @@ type TreeMap struct {
 	value any
 	root  TreeMapImpl
 	err   error
+	path  []pathSegment
+	conv  *Converter
 }
 
 // ------------------- Constructors -------------------
@@ -29,7 +31,9 @@ func (d *TreeMap) getValue() any {
 	return d.value
 }
 
-func (d *TreeMap) getError() error {
+// Err returns the error that broke the chain, e.g. a *PathError wrapping
+// ErrNotFound for a missing path or an *ErrTypeMismatch, or nil.
+func (d *TreeMap) Err() error {
 	return d.err
 }
 
@@ -37,10 +41,24 @@ func (d *TreeMap) getRoot() TreeMapImpl {
 	return d.root
 }
 
+// Path returns the location of this node relative to its root, e.g. `users[0].email`.
+func (d *TreeMap) Path() string {
+	return formatPath(d.path)
+}
+
 func (d *TreeMap) ToJsonString(pretty bool) string {
 	if d.err != nil {
 		return "{}"
 	}
+	s, err := d.marshalJson(pretty)
+	if err != nil {
+		d.err = err
+		return "{}"
+	}
+	return s
+}
+
+func (d *TreeMap) marshalJson(pretty bool) (string, error) {
 	var (
 		bytes []byte
 		err   error
@@ -51,8 +69,7 @@ func (d *TreeMap) ToJsonString(pretty bool) string {
 		bytes, err = json.Marshal(d.value)
 	}
 	if err != nil {
-		d.err = err
-		return "{}"
+		return "", err
 	}
-	return string(bytes)
+	return string(bytes), nil
 }